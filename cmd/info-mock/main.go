@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/infomock"
+	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+)
+
+func main() {
+	addr := flag.String("addr", "0.0.0.0:8080", "address to listen on")
+	fixturePath := flag.String("fixtures", "./fixtures/info-mock.yaml", "path to JSON or YAML fixture file")
+	latency := flag.Duration("latency", -1, "override latency for every response")
+	errorRate := flag.Float64("error-rate", -1, "override probability of a 500 response")
+	flag.Parse()
+
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+
+	fixture, err := infomock.LoadFixture(*fixturePath)
+	if err != nil {
+		log.Error("failed to load fixtures", sl.Err(err))
+		os.Exit(1)
+	}
+
+	if *latency >= 0 {
+		fixture.Latency = *latency
+	}
+	if *errorRate >= 0 {
+		fixture.ErrorRate = *errorRate
+	}
+
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: infomock.New(fixture, log),
+	}
+
+	go func() {
+		log.Info("starting info mock", slog.String("addr", *addr), slog.Int("songs", len(fixture.Songs)))
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("server failed", sl.Err(err))
+			os.Exit(1)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_ = server.Shutdown(ctx)
+
+	log.Info("info mock stopped")
+}
@@ -8,14 +8,19 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/stepan41k/Testovoe/internal/clients/musicinfo"
+	adminHandler "github.com/stepan41k/Testovoe/internal/http-server/handlers/admin"
 	musicHandler "github.com/stepan41k/Testovoe/internal/http-server/handlers/music"
+	"github.com/stepan41k/Testovoe/internal/http-server/router"
+	"github.com/stepan41k/Testovoe/internal/service/enrichment"
 	musicService "github.com/stepan41k/Testovoe/internal/service/music"
-	"github.com/go-chi/chi"
-	"github.com/go-chi/chi/middleware"
+	"github.com/stepan41k/Testovoe/internal/service/suggest"
 	"github.com/stepan41k/Testovoe/cmd/migrator"
 	"github.com/stepan41k/Testovoe/internal/app"
 	"github.com/stepan41k/Testovoe/internal/config"
+	"github.com/stepan41k/Testovoe/internal/storage/memory"
 	"github.com/stepan41k/Testovoe/internal/storage/postgres"
+	"github.com/stepan41k/Testovoe/internal/storage/sqlite"
 )
 
 const (
@@ -24,6 +29,18 @@ const (
 	envProd = "prod"
 )
 
+const (
+	driverPostgres = "postgres"
+	driverMemory = "memory"
+	driverSQLite = "sqlite"
+)
+
+type Storage interface {
+	musicService.Music
+	enrichment.Jobs
+	suggest.Names
+}
+
 func main() {
 	cfg := config.MustLoad()
 
@@ -31,36 +48,32 @@ func main() {
 
 	log.Info("starting application")
 
-	router := chi.NewRouter()
+	store, closeStorage := setupStorage(cfg, log)
 
-	router.Use(middleware.RequestID)
-	router.Use(middleware.Recoverer)
-	router.Use(middleware.URLFormat)
+	infoClient := musicinfo.New(log, cfg.MusicInfo.URL, cfg.MusicInfo.Timeout)
 
-	storagePath := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.Username, cfg.Storage.DBName, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.SSLMode)
+	suggester := suggest.New(store, cfg.Suggest.CacheTTL, log)
+	suggestHandler := musicHandler.NewSuggest(suggester, log)
 
-	pool, err := postgres.New(context.Background(), storagePath)
-	if err != nil {
-		panic(err)
-	}
-	service := musicService.New(pool, log)
+	service := musicService.New(store, suggester, log)
 	handler := musicHandler.New(service, log)
 
-	storagePathForMigrator := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", cfg.Storage.Username, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.DBName, cfg.Storage.SSLMode)
+	enricher := enrichment.New(store, infoClient, cfg.Enrichment, log)
+	admin := adminHandler.New(enricher, log)
 
-	migrator.NewMigrator(storagePathForMigrator, os.Getenv("MY_MIGRATIONS_PATH"))
+	routes := router.New(handler, suggestHandler, admin, cfg.Admin.Token, log)
 
-	router.Route("/song", func(r chi.Router) {
-		r.Get("/songs", handler.GetSongs(context.Background()))
-		r.Get("/text", handler.GetTextSong(context.Background()))
-		r.Delete("/delete", handler.DeleteSong(context.Background()))
-		r.Put("/update", handler.UpdateSong(context.Background()))
-		r.Post("/new", handler.AddNewSong(context.Background()))
-	})
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+
+	go func() {
+		enricher.Run(workersCtx)
+		close(workersDone)
+	}()
 
 	log.Info("starting server")
 
-	application := app.New(log, cfg, router)
+	application := app.New(log, cfg, routes)
 
 	go func() {
 		application.HTTPServer.Run()
@@ -75,7 +88,10 @@ func main() {
 
 	application.HTTPServer.Stop(context.Background())
 
-	postgres.Close(context.Background(), pool)
+	stopWorkers()
+	<-workersDone
+
+	closeStorage()
 
 	log.Info("application stopped")
 
@@ -100,4 +116,41 @@ func setupLogger(env string) *slog.Logger {
 	}
 
 	return log
-}
\ No newline at end of file
+}
+
+func setupStorage(cfg *config.Config, log *slog.Logger) (Storage, func()) {
+	switch cfg.Storage.Driver {
+	case driverMemory:
+		log.Info("using in-memory storage")
+
+		store := memory.New()
+
+		return store, func() { memory.Close(store) }
+	case driverSQLite:
+		log.Info("using sqlite storage", slog.String("path", cfg.Storage.Path))
+
+		store, err := sqlite.New(context.Background(), cfg.Storage.Path)
+		if err != nil {
+			panic(err)
+		}
+
+		return store, func() { sqlite.Close(context.Background(), store) }
+	case driverPostgres:
+		log.Info("using postgres storage")
+
+		storagePath := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.Username, cfg.Storage.DBName, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.SSLMode)
+
+		storagePathForMigrator := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", cfg.Storage.Username, os.Getenv("MY_DB_PASSWORD"), cfg.Storage.Host, cfg.Storage.Port, cfg.Storage.DBName, cfg.Storage.SSLMode)
+
+		migrator.NewMigrator(storagePathForMigrator, os.Getenv("MY_MIGRATIONS_PATH"))
+
+		pool, err := postgres.New(context.Background(), storagePath)
+		if err != nil {
+			panic(err)
+		}
+
+		return pool, func() { postgres.Close(context.Background(), pool) }
+	default:
+		panic(fmt.Sprintf("unknown storage driver: %s", cfg.Storage.Driver))
+	}
+}
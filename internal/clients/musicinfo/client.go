@@ -0,0 +1,118 @@
+package musicinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const releaseDateLayout = "02.01.2006"
+
+var (
+	ErrSongNotFound    = errors.New("song not found in music info")
+	ErrBadRequest      = errors.New("music info rejected request")
+	ErrUpstream        = errors.New("music info internal error")
+	ErrTimeout         = errors.New("music info timeout")
+	ErrInvalidResponse = errors.New("music info invalid response")
+)
+
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	log        *slog.Logger
+}
+
+func New(log *slog.Logger, baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+		log:        log,
+	}
+}
+
+func (c *Client) SongInfo(ctx context.Context, group, song string) (SongDetail, error) {
+	const op = "clients.musicinfo.SongInfo"
+
+	log := c.log.With(
+		slog.String("op", op),
+		slog.String("group", group),
+		slog.String("song", song),
+	)
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return SongDetail{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u = u.JoinPath("info")
+	u.RawQuery = url.Values{"group": {group}, "song": {song}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return SongDetail{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isTimeout(err) {
+			return SongDetail{}, fmt.Errorf("%s: %w", op, ErrTimeout)
+		}
+
+		return SongDetail{}, fmt.Errorf("%s: %w: %w", op, ErrUpstream, err)
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return SongDetail{}, fmt.Errorf("%s: %w", op, ErrSongNotFound)
+	case resp.StatusCode == http.StatusBadRequest:
+		return SongDetail{}, fmt.Errorf("%s: %w", op, ErrBadRequest)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return SongDetail{}, fmt.Errorf("%s: %w: status %d", op, ErrUpstream, resp.StatusCode)
+	default:
+		// Other statuses, such as 429 Too Many Requests, are not about the song and may pass.
+		return SongDetail{}, fmt.Errorf("%s: %w: unexpected status %d", op, ErrUpstream, resp.StatusCode)
+	}
+
+	var detail SongDetail
+
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		if isTimeout(err) {
+			return SongDetail{}, fmt.Errorf("%s: %w", op, ErrTimeout)
+		}
+
+		return SongDetail{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidResponse, err)
+	}
+
+	if _, err := time.Parse(releaseDateLayout, detail.ReleaseDate); err != nil {
+		return SongDetail{}, fmt.Errorf("%s: %w: release date %q", op, ErrInvalidResponse, detail.ReleaseDate)
+	}
+
+	log.Debug("got song info")
+
+	return detail, nil
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,169 @@
+package musicinfo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/infomock"
+)
+
+func TestSongInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    SongDetail
+		wantErr error
+	}{
+		{
+			name: "found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/info" || r.URL.Query().Get("group") != "Кино" || r.URL.Query().Get("song") != "Группа крови" {
+					t.Errorf("request: got %s, want /info for the song", r.URL)
+				}
+
+				_, _ = w.Write([]byte(`{"releaseDate": "05.01.1988", "text": "Тёплое место", "link": "https://example.com"}`))
+			},
+			want: SongDetail{ReleaseDate: "05.01.1988", Text: "Тёплое место", Link: "https://example.com"},
+		},
+		{
+			name:    "not found",
+			handler: status(http.StatusNotFound),
+			wantErr: ErrSongNotFound,
+		},
+		{
+			name:    "bad request",
+			handler: status(http.StatusBadRequest),
+			wantErr: ErrBadRequest,
+		},
+		{
+			name:    "internal error",
+			handler: status(http.StatusInternalServerError),
+			wantErr: ErrUpstream,
+		},
+		{
+			name:    "unavailable",
+			handler: status(http.StatusServiceUnavailable),
+			wantErr: ErrUpstream,
+		},
+		{
+			name:    "too many requests",
+			handler: status(http.StatusTooManyRequests),
+			wantErr: ErrUpstream,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			wantErr: ErrTimeout,
+		},
+		{
+			name:    "malformed body",
+			handler: body(`{"releaseDate": `),
+			wantErr: ErrInvalidResponse,
+		},
+		{
+			name:    "bad date",
+			handler: body(`{"releaseDate": "2006-07-16", "text": "Ooh baby"}`),
+			wantErr: ErrInvalidResponse,
+		},
+		{
+			name:    "no date",
+			handler: body(`{"text": "Ooh baby"}`),
+			wantErr: ErrInvalidResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			client := New(slog.New(slog.DiscardHandler), server.URL, 100*time.Millisecond)
+
+			got, err := client.SongInfo(context.Background(), "Кино", "Группа крови")
+			if !errors.Is(err, tt.wantErr) || err != nil && tt.wantErr == nil {
+				t.Fatalf("SongInfo: got error %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("SongInfo: got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSongInfoAgainstMock(t *testing.T) {
+	always := 1.0
+
+	mock := infomock.New(infomock.Fixture{
+		Seed: 1,
+		Songs: []infomock.Song{
+			{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com"},
+			{Group: "Muse", Song: "Hysteria", Status: http.StatusBadGateway},
+			{Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009", ErrorRate: &always},
+			{Group: "Muse", Song: "Slow", ReleaseDate: "07.09.2009", Latency: time.Second},
+		},
+	}, slog.New(slog.DiscardHandler))
+
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	client := New(slog.New(slog.DiscardHandler), server.URL, 100*time.Millisecond)
+
+	tests := []struct {
+		song    string
+		want    SongDetail
+		wantErr error
+	}{
+		{song: "Starlight", want: SongDetail{ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com"}},
+		{song: "Hysteria", wantErr: ErrUpstream},
+		{song: "Uprising", wantErr: ErrUpstream},
+		{song: "Slow", wantErr: ErrTimeout},
+		{song: "Unknown", wantErr: ErrSongNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := client.SongInfo(context.Background(), "Muse", tt.song)
+		if !errors.Is(err, tt.wantErr) || err != nil && tt.wantErr == nil || got != tt.want {
+			t.Fatalf("SongInfo %s: got %+v, %v, want %+v, %v", tt.song, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSongInfoCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := New(slog.New(slog.DiscardHandler), server.URL, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.SongInfo(ctx, "Muse", "Starlight"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("SongInfo past the context deadline: got %v, want ErrTimeout", err)
+	}
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}
+}
+
+func body(text string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(text))
+	}
+}
@@ -10,23 +10,52 @@ import (
 )
 
 type Config struct {
-	Env     string     `yaml:"env" env-default:"local"`
-	Server  HTTPServer `yaml:"http_server"`
-	Storage DataBase   `yaml:"db"`
+	Env        string     `yaml:"env" env-default:"local"`
+	Server     HTTPServer `yaml:"http_server"`
+	Storage    DataBase   `yaml:"db"`
+	MusicInfo  MusicInfo  `yaml:"music_info"`
+	Enrichment Enrichment `yaml:"enrichment"`
+	Suggest    Suggest    `yaml:"suggest"`
+	Admin      Admin      `yaml:"admin"`
 }
 
 type HTTPServer struct {
-	Port string `yaml:"server_port"`
+	Port         string        `yaml:"server_port"`
 	Timeout      time.Duration `yaml:"timeout" env-default:"4s"`
 	Idle_timeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
 }
 
 type DataBase struct {
+	Driver   string `yaml:"driver" env:"DB_DRIVER" env-default:"postgres"`
 	Host     string `yaml:"host"`
 	Port     string `yaml:"port"`
 	Username string `yaml:"username"`
 	DBName   string `yaml:"dbname"`
 	SSLMode  string `yaml:"sslmode"`
+	Path     string `yaml:"path" env-default:"./music-library.db"`
+}
+
+type MusicInfo struct {
+	URL     string        `yaml:"url" env:"MUSIC_INFO_URL"`
+	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+}
+
+type Enrichment struct {
+	Workers      int           `yaml:"workers" env-default:"2"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	Lease        time.Duration `yaml:"lease" env-default:"30s"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"5"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" env-default:"2s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env-default:"5m"`
+}
+
+// Admin guards the /admin routes. They are not served at all while Token is empty.
+type Admin struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN"`
+}
+
+type Suggest struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env-default:"30s"`
 }
 
 func MustLoad() *Config {
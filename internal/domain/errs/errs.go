@@ -0,0 +1,66 @@
+// Package errs is the error taxonomy shared by the storage, service and handler layers.
+// Storage backends translate driver errors into these, so callers only match on errs.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSongNotFound   = errors.New("song not found")
+	ErrSongExists     = errors.New("song already exists")
+	ErrInvalidSong    = errors.New("invalid song")
+	ErrNoChanges      = errors.New("no changes")
+	ErrNoJobs         = errors.New("no jobs")
+	ErrNoSyncedLyrics = errors.New("no synced lyrics")
+	ErrNoTranslation  = errors.New("no translation")
+)
+
+// SongError ties an error to the song it is about. The song is known either by id or by band and title.
+type SongError struct {
+	ID        int64
+	BandName  string
+	SongTitle string
+	Err       error
+}
+
+func (e *SongError) Error() string {
+	if e.ID != 0 {
+		return fmt.Sprintf("song %d: %s", e.ID, e.Err)
+	}
+
+	return fmt.Sprintf("song %q by %q: %s", e.SongTitle, e.BandName, e.Err)
+}
+
+func (e *SongError) Unwrap() error {
+	return e.Err
+}
+
+func ByID(id int64, err error) error {
+	return &SongError{ID: id, Err: err}
+}
+
+func ByName(band, title string, err error) error {
+	return &SongError{BandName: band, SongTitle: title, Err: err}
+}
+
+// ConstraintError is a database constraint violation. It matches both the domain error it was
+// mapped to and the driver error that caused it.
+type ConstraintError struct {
+	Constraint string
+	Err        error
+	Cause      error
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return fmt.Sprintf("%s: %s", e.Err, e.Cause)
+	}
+
+	return fmt.Sprintf("%s: constraint %s: %s", e.Err, e.Constraint, e.Cause)
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Err, e.Cause}
+}
@@ -0,0 +1,9 @@
+package models
+
+type EnrichmentJob struct {
+	ID        int64
+	SongID    int64
+	BandName  string
+	SongTitle string
+	Attempts  int
+}
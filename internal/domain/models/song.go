@@ -1,25 +1,290 @@
 package models
 
+import "encoding/json"
+
+const (
+	SongStatusPending  = "pending"
+	SongStatusEnriched = "enriched"
+	SongStatusFailed   = "failed"
+)
+
 type Song struct {
-	BandName    string `json:"band_name" validate:"required" db:"band"`
-	SongTitle   string `json:"song_title" validate:"required" db:"song"`
-	ReleaseDate string `json:"release_date,omitempty" db:"release"`
-	Lyrics      string `json:"lyrics,omitempty" db:"lyrics"`
-	Link        string `json:"link,omitempty" db:"link"`
+	ID          int64   `json:"id,omitempty" db:"id"`
+	BandName    string  `json:"band_name" validate:"required" db:"band"`
+	SongTitle   string  `json:"song_title" validate:"required" db:"song"`
+	ReleaseDate string  `json:"release_date,omitempty" db:"release"`
+	Lyrics      string  `json:"lyrics,omitempty" db:"lyrics"`
+	Link        string  `json:"link,omitempty" db:"link"`
+	Status      string  `json:"status,omitempty" db:"status"`
+	Language    string  `json:"language,omitempty" db:"language"`
+	Snippet     string  `json:"snippet,omitempty"`
+	Rank        float64 `json:"rank,omitempty"`
 }
 
+type MatchMode string
+
+const (
+	MatchExact     MatchMode = "exact"
+	MatchPrefix    MatchMode = "prefix"
+	MatchContains  MatchMode = "contains"
+	MatchIExact    MatchMode = "iexact"
+	MatchIPrefix   MatchMode = "iprefix"
+	MatchIContains MatchMode = "icontains"
+)
+
+type SearchMode string
+
+const (
+	// SearchWebsearch accepts "quoted phrases", or between alternatives and -word to exclude a word.
+	SearchWebsearch SearchMode = "websearch"
+	SearchPhrase    SearchMode = "phrase"
+	SearchPlain     SearchMode = "plain"
+)
+
+// SongFilter selects and pages the song listing. Page is bounded so that its offset cannot
+// overflow, deeper listings page with Cursor.
 type SongFilter struct {
-	BandName    string `json:"band_name,omitempty" db:"band"`
-	SongTitle   string `json:"song_title,omitempty" db:"song"`
-	ReleaseDate string `json:"release_date,omitempty" db:"release"`
-	Later       bool   `json:"bigger,omitempty"`
-	Lyrics      string `json:"lyrics,omitempty" db:"lyrics"`
-	Page        int    `json:"page,omitempty"`
-	PageSize    int    `json:"page_size,omitempty"`
+	BandName             string     `json:"band_name,omitempty" db:"band"`
+	BandMatch            MatchMode  `json:"band_match,omitempty" validate:"omitempty,oneof=exact prefix contains iexact iprefix icontains"`
+	SongTitle            string     `json:"song_title,omitempty" db:"song"`
+	TitleMatch           MatchMode  `json:"song_title_match,omitempty" validate:"omitempty,oneof=exact prefix contains iexact iprefix icontains"`
+	ReleaseDate          string     `json:"release_date,omitempty" db:"release"`
+	Later                bool       `json:"bigger,omitempty"`
+	ReleaseFrom          string     `json:"release_from,omitempty"`
+	ReleaseFromExclusive bool       `json:"release_from_exclusive,omitempty"`
+	ReleaseTo            string     `json:"release_to,omitempty"`
+	ReleaseToExclusive   bool       `json:"release_to_exclusive,omitempty"`
+	Lyrics               string     `json:"lyrics,omitempty" db:"lyrics"`
+	LyricsMatch          MatchMode  `json:"lyrics_match,omitempty" validate:"omitempty,oneof=exact prefix contains iexact iprefix icontains"`
+	Search               string     `json:"search,omitempty"`
+	SearchMode           SearchMode `json:"search_mode,omitempty" validate:"omitempty,oneof=websearch phrase plain"`
+	SearchLanguage       string     `json:"search_language,omitempty" validate:"omitempty,oneof=simple english russian"`
+	Fuzzy                bool       `json:"fuzzy,omitempty"`
+	Similarity           float64    `json:"similarity,omitempty" validate:"omitempty,gt=0,lte=1"`
+	HasLink              *bool      `json:"has_link,omitempty"`
+	Sort                 string     `json:"sort,omitempty"`
+	Cursor               string     `json:"cursor,omitempty"`
+	Page                 int        `json:"page,omitempty" validate:"min=0,max=100000"`
+	PageSize             int        `json:"page_size,omitempty" validate:"min=0,max=100"`
+	Count                bool       `json:"count,omitempty"`
+}
+
+// DefaultSimilarity is the trigram similarity a fuzzy match needs when the filter sets none.
+// It is lower than the pg_trgm default so that a single typo in a short name still matches.
+const DefaultSimilarity = 0.2
+
+// Threshold returns the trigram similarity a fuzzy match needs.
+func (f SongFilter) Threshold() float64 {
+	if f.Similarity > 0 {
+		return f.Similarity
+	}
+
+	return DefaultSimilarity
+}
+
+// Ranked reports whether the listing has a relevance rank: the score of a lyrics search plus the
+// similarity of fuzzily matched band and title.
+func (f SongFilter) Ranked() bool {
+	return f.Search != "" || f.Fuzzy && (f.BandName != "" || f.SongTitle != "")
+}
+
+// SongList is one page of songs. Total is set only when the filter asked for a count, DidYouMean
+// only when no song matched the band and title exactly.
+type SongList struct {
+	Songs      []Song
+	NextCursor string
+	PrevCursor string
+	Page       int
+	PageSize   int
+	Total      *int64
+	DidYouMean *DidYouMean
+}
+
+// DidYouMean holds the existing band and title closest to the ones a listing asked for. A field
+// is empty when nothing is similar enough.
+type DidYouMean struct {
+	BandName  string `json:"band_name,omitempty"`
+	SongTitle string `json:"song_title,omitempty"`
+}
+
+// Verse is one verse of a song with its number and the number of verses in the song. Language is
+// the language of the text, empty when it is not known.
+type Verse struct {
+	Number   int
+	Kind     string
+	Text     string
+	Total    int
+	Language string
+}
+
+// Section is one verse of a song's structure. RepeatOf is the number of the first section with the
+// same text, or zero when the section is the first of its text.
+type Section struct {
+	Number   int    `json:"number"`
+	Kind     string `json:"kind"`
+	Text     string `json:"text"`
+	RepeatOf int    `json:"repeat_of,omitempty"`
+}
+
+// Structure lists the sections of a song's lyrics in order.
+type Structure struct {
+	SongID   int64     `json:"song_id"`
+	Sections []Section `json:"sections"`
 }
 
+// LyricsFormat is how lyrics are written out.
+type LyricsFormat string
+
+const (
+	FormatJSON LyricsFormat = "json"
+	FormatText LyricsFormat = "text"
+)
+
+// SongLyrics asks for the lyrics of a song. A positive Verse selects that verse alone; otherwise
+// Verses selects a range such as 2-4, 3, 2- or -4, and the whole lyrics when empty, paged by Page
+// and PageSize. Lang asks for the translation to a BCP 47 language instead of the original lyrics.
 type SongLyrics struct {
-	BandName  string `json:"band_name" validate:"required"`
-	SongTitle string `json:"song_title" validate:"required"`
-	Verse     int    `json:"verse" validate:"required"`
+	BandName  string       `json:"band_name" validate:"required"`
+	SongTitle string       `json:"song_title" validate:"required"`
+	Verse     int          `json:"verse" validate:"min=0"`
+	Verses    string       `json:"verses,omitempty" validate:"excluded_with=Verse"`
+	Page      int          `json:"page,omitempty" validate:"min=0"`
+	PageSize  int          `json:"page_size,omitempty" validate:"min=0,max=100"`
+	Format    LyricsFormat `json:"format,omitempty" validate:"omitempty,oneof=json text"`
+	Lang      string       `json:"lang,omitempty"`
+}
+
+// SyncedFormat is how synced lyrics are written out.
+type SyncedFormat string
+
+const (
+	SyncedJSON SyncedFormat = "json"
+	SyncedLRC  SyncedFormat = "lrc"
+	SyncedVTT  SyncedFormat = "vtt"
+)
+
+// SyncedLine is a line of time-synced lyrics, sung from TimeMs milliseconds into the song. An empty
+// line marks an instrumental break.
+type SyncedLine struct {
+	Number int    `json:"number"`
+	TimeMs int64  `json:"time_ms"`
+	Text   string `json:"text"`
+}
+
+// SyncedQuery asks for the synced lyrics of a song in one of the SyncedFormat formats.
+type SyncedQuery struct {
+	Format SyncedFormat `json:"format,omitempty" validate:"omitempty,oneof=json lrc vtt"`
+}
+
+// SyncedPosition is what is sung TimeMs milliseconds into the song. Line is nil before the first
+// line and Next is nil after the last.
+type SyncedPosition struct {
+	TimeMs int64       `json:"time_ms"`
+	Line   *SyncedLine `json:"line"`
+	Next   *SyncedLine `json:"next,omitempty"`
+}
+
+// VersePage is one page of the verses of a song's lyrics. Total counts the verses of the whole
+// requested range.
+type VersePage struct {
+	Verses   []Section
+	Page     int
+	PageSize int
+	Total    int
+	HasNext  bool
+	Language string
+}
+
+// Translation is the lyrics of a song translated to a BCP 47 language.
+type Translation struct {
+	SongID   int64  `json:"song_id"`
+	Language string `json:"language"`
+	Lyrics   string `json:"lyrics"`
+}
+
+// TranslationBody is the body of a request adding or updating a translation.
+type TranslationBody struct {
+	Lyrics string `json:"lyrics" validate:"required"`
+}
+
+// PatchField is one field of a partial update. A field missing from the JSON document is left
+// alone, an explicit null clears it.
+type PatchField struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+func (f *PatchField) UnmarshalJSON(data []byte) error {
+	f.Set = true
+
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Of returns a field set to value.
+func Of(value string) PatchField {
+	return PatchField{Set: true, Value: value}
+}
+
+type SongPatch struct {
+	BandName    PatchField `json:"band_name"`
+	SongTitle   PatchField `json:"song_title"`
+	ReleaseDate PatchField `json:"release_date"`
+	Lyrics      PatchField `json:"lyrics"`
+	Link        PatchField `json:"link"`
+	Language    PatchField `json:"language"`
+}
+
+func (p SongPatch) Empty() bool {
+	return !p.BandName.Set && !p.SongTitle.Set && !p.ReleaseDate.Set && !p.Lyrics.Set && !p.Link.Set && !p.Language.Set
+}
+
+// LegacyPatch maps the body of the old update endpoint, where every non-empty field is a change.
+func LegacyPatch(song Song) SongPatch {
+	var patch SongPatch
+
+	for _, field := range []struct {
+		value string
+		to    *PatchField
+	}{
+		{song.ReleaseDate, &patch.ReleaseDate},
+		{song.Lyrics, &patch.Lyrics},
+		{song.Link, &patch.Link},
+		{song.Language, &patch.Language},
+	} {
+		if field.value != "" {
+			*field.to = Of(field.value)
+		}
+	}
+
+	return patch
+}
+
+// VerseQuery looks for the verses containing a phrase. A page holds the matches of up to PageSize songs.
+type VerseQuery struct {
+	Phrase   string `json:"phrase" validate:"required"`
+	BandName string `json:"band_name,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+	PageSize int    `json:"page_size,omitempty" validate:"min=0,max=100"`
+}
+
+// VerseMatch is a line containing the searched phrase. Verse is the number GetTextSong takes, Line
+// counts the lines of that verse from one.
+type VerseMatch struct {
+	SongID    int64  `json:"song_id"`
+	BandName  string `json:"band_name"`
+	SongTitle string `json:"song_title"`
+	Verse     int    `json:"verse"`
+	Line      int    `json:"line"`
+	Text      string `json:"text"`
+}
+
+type VerseMatches struct {
+	Matches    []VerseMatch
+	NextCursor string
 }
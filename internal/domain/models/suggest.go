@@ -0,0 +1,28 @@
+package models
+
+type NameKind string
+
+const (
+	NameBand NameKind = "band"
+	NameSong NameKind = "song"
+)
+
+type SuggestOrder string
+
+const (
+	SuggestPopular SuggestOrder = "popular"
+	SuggestAlpha   SuggestOrder = "alpha"
+)
+
+// Name is a distinct band or song title with the number of songs carrying it.
+type Name struct {
+	Value string `json:"value"`
+	Songs int64  `json:"songs"`
+}
+
+type SuggestQuery struct {
+	Query string       `json:"q" validate:"required"`
+	Kind  NameKind     `json:"kind" validate:"required,oneof=band song"`
+	Limit int          `json:"limit,omitempty" validate:"min=0,max=50"`
+	Order SuggestOrder `json:"order,omitempty" validate:"omitempty,oneof=popular alpha"`
+}
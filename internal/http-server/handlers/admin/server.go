@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+)
+
+type Enrichment interface {
+	RequeueFailed(ctx context.Context) (count int64, err error)
+}
+
+type AdminHandler struct {
+	enrichment Enrichment
+	log        *slog.Logger
+}
+
+func New(enrichment Enrichment, log *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		enrichment: enrichment,
+		log:        log,
+	}
+}
+
+func (a *AdminHandler) RequeueFailedJobs(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.admin.RequeueFailedJobs"
+
+		log := a.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		count, err := a.enrichment.RequeueFailed(ctx)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   count,
+		})
+	}
+}
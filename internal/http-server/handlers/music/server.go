@@ -4,25 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 	"github.com/stepan41k/Testovoe/internal/domain/models"
-	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	"github.com/stepan41k/Testovoe/internal/lib/api/query"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
 	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
-	"github.com/stepan41k/Testovoe/internal/service"
 )
 
 type Music interface {
-	GetSongs(ctx context.Context, song models.SongFilter) (songs []models.Song, err error)
-	GetTextSong(ctx context.Context, song models.SongLyrics) (verse string, err error)
+	GetSongs(ctx context.Context, song models.SongFilter) (songs models.SongList, err error)
+	GetTextSong(ctx context.Context, song models.SongLyrics) (verse models.Verse, err error)
+	GetTextVerses(ctx context.Context, song models.SongLyrics) (page models.VersePage, err error)
 	DeleteSong(ctx context.Context, song models.Song) (id int64, err error)
 	UpdateSong(ctx context.Context, songDetails models.Song) (id int64, err error)
 	AddNewSong(ctx context.Context, song models.Song) (id int64, err error)
+	GetSong(ctx context.Context, id int64) (song models.Song, err error)
+	ReplaceSong(ctx context.Context, id int64, song models.Song) (replaced models.Song, err error)
+	PatchSong(ctx context.Context, id int64, patch models.SongPatch) (patched models.Song, err error)
+	DeleteSongByID(ctx context.Context, id int64) error
+	GetLyrics(ctx context.Context, id int64) (text string, err error)
+	GetVerse(ctx context.Context, id int64, n int) (verse models.Verse, err error)
+	GetStructure(ctx context.Context, id int64) (structure models.Structure, err error)
+	FindVerses(ctx context.Context, query models.VerseQuery) (matches models.VerseMatches, err error)
+	ImportLRC(ctx context.Context, id int64, text string) (lines []models.SyncedLine, err error)
+	GetSyncedLyrics(ctx context.Context, id int64) (lines []models.SyncedLine, err error)
+	ExportSynced(ctx context.Context, id int64, format models.SyncedFormat) (text string, err error)
+	SyncedAt(ctx context.Context, id int64, t time.Duration) (position models.SyncedPosition, err error)
+	DeleteSyncedLyrics(ctx context.Context, id int64) error
+	SaveTranslation(ctx context.Context, id int64, language, text string) (translation models.Translation, created bool, err error)
+	GetTranslation(ctx context.Context, id int64, language string) (translation models.Translation, err error)
+	GetTranslations(ctx context.Context, id int64) (translations []models.Translation, err error)
+	DeleteTranslation(ctx context.Context, id int64, language string) error
 }
 
 type MusicHandler struct {
@@ -49,20 +71,40 @@ func (m *MusicHandler) GetSongs(ctx context.Context) http.HandlerFunc {
 
 		var req models.SongFilter
 
-		err := render.Decode(r, &req)
+		err := DecodeQuery(w, r, log, &req)
 		flag := CheckForErrors(req, w, r, log, err)
 		if flag {
 			return
 		}
 
-		songs, err := m.music.GetSongs(ctx, req)
+		if _, err := releasedate.FilterRange(req); err != nil {
+			if errors.Is(err, releasedate.ErrInvalidRange) {
+				apiErr.Render(w, r, log, apiErr.Unprocessable("release date range is empty", err))
+
+				return
+			}
+
+			apiErr.Render(w, r, log, apiErr.BadRequest("invalid release date, expected DD.MM.YYYY, MM.YYYY or YYYY", err))
+
+			return
+		}
+
+		keys, err := sorting.ForSearch(req.Sort, req.Ranked())
 		if err != nil {
-			log.Error("internal error")
+			apiErr.Render(w, r, log, apiErr.Unprocessable("invalid sort, expected comma separated band_name, song_title, release_date, updated or rank (with a search or fuzzy match) with optional - prefix", err))
 
-			render.JSON(w, r, resp.Response{
-				Status: http.StatusInternalServerError,
-				Error: "internal error",
-			})
+			return
+		}
+
+		if _, err := cursor.Decode(req.Cursor, keys); err != nil {
+			apiErr.Render(w, r, log, apiErr.BadRequest("invalid cursor, it must come from a previous response with the same sort", err))
+
+			return
+		}
+
+		songs, err := m.music.GetSongs(ctx, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
 
 			return
 		}
@@ -71,7 +113,16 @@ func (m *MusicHandler) GetSongs(ctx context.Context) http.HandlerFunc {
 
 		render.JSON(w, r, resp.Response{
 			Status: http.StatusOK,
-			Data: songs,
+			Data: songs.Songs,
+			NextCursor: songs.NextCursor,
+			PrevCursor: songs.PrevCursor,
+			Pagination: &resp.Pagination{
+				Page: songs.Page,
+				PageSize: songs.PageSize,
+				HasNext: songs.NextCursor != "",
+				Total: songs.Total,
+			},
+			DidYouMean: songs.DidYouMean,
 		})	
 	}
 }
@@ -88,29 +139,79 @@ func (m *MusicHandler) GetTextSong(ctx context.Context) http.HandlerFunc {
 
 		var req models.SongLyrics
 
-		err := render.Decode(r, &req)
+		err := DecodeQuery(w, r, log, &req)
 		flag := CheckForErrors(req, w, r, log, err)
 		if flag {
 			return
 		}
 
-		verse, err := m.music.GetTextSong(ctx, req)
+		if !validLanguage(w, r, log, req.Lang) {
+			return
+		}
 
-		if err != nil {
-			log.Error("internal error")
+		if _, err := lyrics.ParseRange(req.Verses); err != nil {
+			apiErr.Render(w, r, log, apiErr.Unprocessable("invalid verse range, expected a verse number or a range such as 2-4, 2- or -4", err))
+
+			return
+		}
+
+		if req.Verse > 0 {
+			verse, err := m.music.GetTextSong(ctx, req)
+
+			if err != nil {
+				apiErr.Render(w, r, log, err)
+
+				return
+			}
+
+			if verse.Language != "" {
+				w.Header().Set("Content-Language", verse.Language)
+			}
+
+			if req.Format == models.FormatText {
+				render.PlainText(w, r, verse.Text)
+
+				return
+			}
 
 			render.JSON(w, r, resp.Response{
-				Status: http.StatusInternalServerError,
-				Error: "internal error",
+				Status: http.StatusOK,
+				Data: verse.Text,
+				Verse: versePosition(verse),
 			})
 
 			return
 		}
 
+		page, err := m.music.GetTextVerses(ctx, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		if page.Language != "" {
+			w.Header().Set("Content-Language", page.Language)
+		}
+
+		if req.Format == models.FormatText {
+			render.PlainText(w, r, versesText(w, r, page))
+
+			return
+		}
+
+		total := int64(page.Total)
+
 		render.JSON(w, r, resp.Response{
 			Status: http.StatusOK,
-			Data: verse,
-		})	
+			Data: page.Verses,
+			Pagination: &resp.Pagination{
+				Page: page.Page,
+				PageSize: page.PageSize,
+				HasNext: page.HasNext,
+				Total: &total,
+			},
+		})
 	}
 }
 
@@ -134,12 +235,7 @@ func (m *MusicHandler) DeleteSong(ctx context.Context) http.HandlerFunc {
 
 		songID, err := m.music.DeleteSong(ctx, req)
 		if err != nil {
-			log.Error("internal error")
-
-			render.JSON(w, r, resp.Response{
-				Status: http.StatusInternalServerError,
-				Error: "internal error",
-			})
+			apiErr.Render(w, r, log, err)
 
 			return
 		}
@@ -171,12 +267,7 @@ func (m *MusicHandler) UpdateSong(ctx context.Context) http.HandlerFunc {
 
 		songID, err := m.music.UpdateSong(ctx, req)
 		if err != nil {
-			log.Error("internal error", sl.Err(err))
-
-			render.JSON(w, r, resp.Response{
-				Status: http.StatusInternalServerError,
-				Error: "internal error",
-			})
+			apiErr.Render(w, r, log, err)
 
 			return
 		}
@@ -208,66 +299,53 @@ func (m *MusicHandler) AddNewSong(ctx context.Context) http.HandlerFunc {
 
 		songID, err := m.music.AddNewSong(ctx, req)
 		if err != nil {
-			if errors.Is(err, service.ErrSongExists) {
-				log.Error("song already exists")
-
-				render.JSON(w, r, resp.Response{
-					Status: http.StatusConflict,
-					Error: "song already exists",
-				})
-
-				return
-			}
-
-			log.Error("internal error", sl.Err(err))
-
-			render.JSON(w, r, resp.Response{
-				Status: http.StatusInternalServerError,
-				Error: "internal error",
-			})
+			apiErr.Render(w, r, log, err)
 
 			return
 		}
 
+		render.Status(r, http.StatusCreated)
 		render.JSON(w, r, resp.Response{
-			Status: http.StatusOK,
+			Status: http.StatusCreated,
 			Data: songID,
 		})
 	}
 }
 
 
-func CheckForErrors(req any, w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) bool {
+// DecodeQuery reads a GET request from its query parameters. Requests without a query string
+// but with a JSON body are still decoded from the body, marked deprecated.
+func DecodeQuery(w http.ResponseWriter, r *http.Request, log *slog.Logger, req any) error {
+	if r.URL.RawQuery == "" && r.ContentLength != 0 {
+		log.Warn("request parameters sent in the body, which is deprecated for GET requests")
 
-	if err != nil {
-		if errors.Is(err, io.EOF) {
-			log.Error("request body is empty")
+		w.Header().Set("Deprecation", "true")
 
-			render.JSON(w, r, resp.Response{
-				Status: http.StatusConflict,
-				Error: "empty request",
-			})
+		return render.Decode(r, req)
+	}
 
-			return true
-		}
+	return query.Decode(r.URL.Query(), req)
+}
 
-		log.Error("failed to decode request")
-		render.JSON(w, r, resp.Response{
-			Status: http.StatusBadRequest,
-			Error: "failed to decode request",
-		})
-		return true
-	}
 
-	if err := validator.New().Struct(req); err != nil {
-		validateErr := err.(validator.ValidationErrors)
-				
-		log.Error("invalid request", sl.Err(err))
+// CheckForErrors renders the problem for a failed decode or an invalid request and reports
+// whether the handler has to stop.
+func CheckForErrors(req any, w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) bool {
+	var paramErr *query.ParamError
+
+	switch {
+	case errors.As(err, &paramErr):
+	case err != nil:
+		err = apiErr.Decode(err)
+	default:
+		err = validator.New().Struct(req)
+	}
 
-		render.JSON(w, r, resp.ValidationError(validateErr))
+	if err != nil {
+		apiErr.Render(w, r, log, err)
 
 		return true
 	}
 
 	return false
-}
\ No newline at end of file
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+	musicService "github.com/stepan41k/Testovoe/internal/service/music"
+	"github.com/stepan41k/Testovoe/internal/service/suggest"
+	"github.com/stepan41k/Testovoe/internal/storage/memory"
+)
+
+func TestStatusCodes(t *testing.T) {
+	router := testRouter(memory.New())
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{name: "created", method: http.MethodPost, target: "/v1/songs", body: `{"band_name": "Muse", "song_title": "Starlight"}`, status: http.StatusCreated},
+		{name: "found", method: http.MethodGet, target: "/v1/songs/1", status: http.StatusOK},
+		{name: "not found", method: http.MethodGet, target: "/v1/songs/42", status: http.StatusNotFound},
+		{name: "malformed id", method: http.MethodGet, target: "/v1/songs/abc", status: http.StatusBadRequest},
+		{name: "duplicate", method: http.MethodPost, target: "/v1/songs", body: `{"band_name": "Muse", "song_title": "Starlight"}`, status: http.StatusConflict},
+		{name: "missing title", method: http.MethodPost, target: "/v1/songs", body: `{"band_name": "Muse"}`, status: http.StatusUnprocessableEntity},
+		{name: "malformed body", method: http.MethodPost, target: "/v1/songs", body: `{"band_name": `, status: http.StatusBadRequest},
+		{name: "malformed release date", method: http.MethodGet, target: "/v1/songs?release_from=soon", status: http.StatusBadRequest},
+		{name: "empty release range", method: http.MethodGet, target: "/v1/songs?release_from=2010&release_to=2000", status: http.StatusUnprocessableEntity},
+		{name: "malformed page", method: http.MethodGet, target: "/v1/songs?page=abc", status: http.StatusBadRequest},
+		{name: "malformed language", method: http.MethodGet, target: "/song/text?band_name=Muse&song_title=Starlight&lang=en_", status: http.StatusUnprocessableEntity},
+		{name: "suggestion", method: http.MethodGet, target: "/v1/suggest?q=mu&kind=band", status: http.StatusOK},
+		{name: "blank suggestion query", method: http.MethodGet, target: "/v1/suggest?q=%20%20&kind=band", status: http.StatusUnprocessableEntity},
+		{name: "malformed cursor", method: http.MethodGet, target: "/v1/songs?cursor=abc", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("%s %s: got %d, want %d: %s", tt.method, tt.target, rec.Code, tt.status, rec.Body)
+			}
+
+			if tt.status < http.StatusBadRequest {
+				return
+			}
+
+			var problem apiErr.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil || problem.Status != tt.status {
+				t.Fatalf("problem: got %+v, %v, want status %d", problem, err, tt.status)
+			}
+
+			if got := rec.Header().Get("Content-Type"); got != apiErr.ContentType {
+				t.Fatalf("content type: got %q, want %q", got, apiErr.ContentType)
+			}
+		})
+	}
+}
+
+func TestTextVerses(t *testing.T) {
+	store := memory.New()
+
+	_, err := store.AddNewSong(context.Background(), models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "one\n\ntwo\n\nthree\n\nfour\n\nfive"})
+	if err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	router := testRouter(store)
+
+	tests := []struct {
+		name       string
+		query      string
+		status     int
+		want       []int
+		pagination resp.Pagination
+		total      int64
+	}{
+		{name: "whole lyrics", query: "", status: http.StatusOK, want: []int{1, 2, 3, 4, 5}, pagination: resp.Pagination{Page: 1, PageSize: 20}, total: 5},
+		{name: "range", query: "verses=2-4", status: http.StatusOK, want: []int{2, 3, 4}, pagination: resp.Pagination{Page: 1, PageSize: 20}, total: 3},
+		{name: "first page of a range", query: "verses=2-4&page_size=2", status: http.StatusOK, want: []int{2, 3}, pagination: resp.Pagination{Page: 1, PageSize: 2, HasNext: true}, total: 3},
+		{name: "last page of a range", query: "verses=2-4&page_size=2&page=2", status: http.StatusOK, want: []int{4}, pagination: resp.Pagination{Page: 2, PageSize: 2}, total: 3},
+		{name: "page past the end", query: "verses=2-&page_size=2&page=9", status: http.StatusOK, want: []int{}, pagination: resp.Pagination{Page: 9, PageSize: 2}, total: 4},
+		{name: "huge page", query: "page_size=100&page=9223372036854775807", status: http.StatusOK, want: []int{}, pagination: resp.Pagination{Page: 9223372036854775807, PageSize: 100}, total: 5},
+		{name: "range ending past the end", query: "verses=4-9", status: http.StatusOK, want: []int{4, 5}, pagination: resp.Pagination{Page: 1, PageSize: 20}, total: 2},
+		{name: "range open to the end", query: "verses=-2", status: http.StatusOK, want: []int{1, 2}, pagination: resp.Pagination{Page: 1, PageSize: 20}, total: 2},
+		{name: "range starting past the end", query: "verses=6-", status: http.StatusNotFound},
+		{name: "reversed range", query: "verses=4-2", status: http.StatusUnprocessableEntity},
+		{name: "verse and verses", query: "verse=2&verses=2-4", status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/song/text?band_name=Muse&song_title=Starlight&"+tt.query, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status: got %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Data       []models.Section `json:"data"`
+				Pagination resp.Pagination  `json:"pagination"`
+			}
+
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			numbers := []int{}
+			for _, section := range got.Data {
+				numbers = append(numbers, section.Number)
+			}
+
+			if !slices.Equal(numbers, tt.want) {
+				t.Fatalf("verses: got %v, want %v", numbers, tt.want)
+			}
+
+			total := got.Pagination.Total
+			got.Pagination.Total = nil
+
+			if got.Pagination != tt.pagination || total == nil || *total != tt.total {
+				t.Fatalf("pagination: got %+v with total %v, want %+v with total %d", got.Pagination, total, tt.pagination, tt.total)
+			}
+		})
+	}
+}
+
+func TestTextVerse(t *testing.T) {
+	store := memory.New()
+
+	_, err := store.AddNewSong(context.Background(), models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "one\n\ntwo\n\nthree"})
+	if err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	router := testRouter(store)
+
+	for verse, want := range map[int]resp.VersePosition{
+		1: {Number: 1, Kind: "verse", Total: 3, Next: 2},
+		2: {Number: 2, Kind: "verse", Total: 3, Prev: 1, Next: 3},
+		3: {Number: 3, Kind: "verse", Total: 3, Prev: 2},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/song/text?band_name=Muse&song_title=Starlight&verse=%d", verse), nil))
+
+		var got struct {
+			Verse resp.VersePosition `json:"verse"`
+		}
+
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || rec.Code != http.StatusOK || got.Verse != want {
+			t.Fatalf("verse %d: got %d %+v, %v, want %+v", verse, rec.Code, got.Verse, err, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/song/text?band_name=Muse&song_title=Starlight&verse=4", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("verse past the end: got %d, want 404", rec.Code)
+	}
+}
+
+// testRouter serves the handlers the tests call, backed by the music service over store.
+func testRouter(store *memory.MStorage) chi.Router {
+	handler := New(musicService.New(store, nil, slog.New(slog.DiscardHandler)), slog.New(slog.DiscardHandler))
+
+	router := chi.NewRouter()
+	router.Get("/v1/songs", handler.GetSongs(context.Background()))
+	router.Post("/v1/songs", handler.CreateSong(context.Background()))
+	router.Get("/v1/songs/{id}", handler.GetSong(context.Background()))
+	router.Get("/song/text", handler.GetTextSong(context.Background()))
+	router.Get("/v1/suggest", NewSuggest(suggest.New(store, time.Minute, slog.New(slog.DiscardHandler)), slog.New(slog.DiscardHandler)).Suggest(context.Background()))
+
+	return router
+}
+
+func TestDecodeQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		want       models.SongFilter
+		wantErr    bool
+		deprecated bool
+	}{
+		{name: "query", target: "/songs?band_name=Muse&page=2", want: models.SongFilter{BandName: "Muse", Page: 2}},
+		{name: "query wins over the body", target: "/songs?page=2", body: `{"band_name": "Placebo"}`, want: models.SongFilter{Page: 2}},
+		{name: "unknown parameter", target: "/songs?colour=red", want: models.SongFilter{}},
+		{name: "bad int", target: "/songs?page=two", wantErr: true},
+		{name: "nothing", target: "/songs", want: models.SongFilter{}},
+		{name: "body", target: "/songs", body: `{"band_name": "Muse", "page": 2}`, want: models.SongFilter{BandName: "Muse", Page: 2}, deprecated: true},
+		{name: "malformed body", target: "/songs", body: `{"page": "two"}`, wantErr: true, deprecated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+
+			var got models.SongFilter
+
+			err := DecodeQuery(rec, req, slog.New(slog.DiscardHandler), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeQuery: got error %v, want error %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DecodeQuery: got %+v, want %+v", got, tt.want)
+			}
+
+			if deprecated := rec.Header().Get("Deprecation") == "true"; deprecated != tt.deprecated {
+				t.Fatalf("Deprecation header: got %v, want %v", deprecated, tt.deprecated)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+)
+
+func (m *MusicHandler) GetSong(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetSong"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		song, err := m.music.GetSong(ctx, id)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   song,
+		})
+	}
+}
+
+func (m *MusicHandler) CreateSong(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.CreateSong"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.Song
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) || !validRelease(w, r, log, req.ReleaseDate) || !validLanguage(w, r, log, req.Language) {
+			return
+		}
+
+		req.ID, req.Status = 0, ""
+
+		id, err := m.music.AddNewSong(ctx, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		song, err := m.music.GetSong(ctx, id)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		w.Header().Set("Location", fmt.Sprintf("%s/%d", strings.TrimSuffix(r.URL.Path, "/"), id))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusCreated,
+			Data:   song,
+		})
+	}
+}
+
+// ReplaceSong handles PUT: the body is the full new state of the song.
+func (m *MusicHandler) ReplaceSong(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.ReplaceSong"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req models.Song
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) || !validRelease(w, r, log, req.ReleaseDate) || !validLanguage(w, r, log, req.Language) {
+			return
+		}
+
+		song, err := m.music.ReplaceSong(ctx, id, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   song,
+		})
+	}
+}
+
+// PatchSong handles PATCH: only the fields present in the body are changed, an explicit null clears a field.
+func (m *MusicHandler) PatchSong(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.PatchSong"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req models.SongPatch
+
+		if err := render.Decode(r, &req); err != nil {
+			CheckForErrors(req, w, r, log, err)
+
+			return
+		}
+
+		for name, field := range map[string]models.PatchField{"band_name": req.BandName, "song_title": req.SongTitle} {
+			if field.Set && field.Value == "" {
+				apiErr.Render(w, r, log, apiErr.Unprocessable(fmt.Sprintf("field %s can not be empty", name), nil))
+
+				return
+			}
+		}
+
+		if !validRelease(w, r, log, req.ReleaseDate.Value) || !validLanguage(w, r, log, req.Language.Value) {
+			return
+		}
+
+		song, err := m.music.PatchSong(ctx, id, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   song,
+		})
+	}
+}
+
+func (m *MusicHandler) DeleteSongByID(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.DeleteSongByID"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		if err := m.music.DeleteSongByID(ctx, id); err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   id,
+		})
+	}
+}
+
+func (m *MusicHandler) GetLyrics(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetLyrics"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		text, err := m.music.GetLyrics(ctx, id)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   text,
+		})
+	}
+}
+
+func (m *MusicHandler) GetVerse(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetVerse"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		n, err := strconv.Atoi(chi.URLParam(r, "n"))
+		if err != nil || n < 1 {
+			apiErr.Render(w, r, log, apiErr.BadRequest("invalid verse number, expected a positive integer", err))
+
+			return
+		}
+
+		verse, err := m.music.GetVerse(ctx, id, n)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   verse.Text,
+			Verse:  versePosition(verse),
+		})
+	}
+}
+
+func (m *MusicHandler) GetStructure(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetStructure"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		structure, err := m.music.GetStructure(ctx, id)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   structure,
+		})
+	}
+}
+
+// versePosition places a verse among the others of its song. Prev and Next are left out at the ends.
+func versePosition(verse models.Verse) *resp.VersePosition {
+	position := &resp.VersePosition{Number: verse.Number, Kind: verse.Kind, Total: verse.Total}
+
+	if verse.Number > 1 {
+		position.Prev = verse.Number - 1
+	}
+
+	if verse.Number < verse.Total {
+		position.Next = verse.Number + 1
+	}
+
+	return position
+}
+
+func songID(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id < 1 {
+		apiErr.Render(w, r, log, apiErr.BadRequest("invalid song id", err))
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+func validRelease(w http.ResponseWriter, r *http.Request, log *slog.Logger, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	if _, err := releasedate.Parse(value); err != nil {
+		apiErr.Render(w, r, log, apiErr.Unprocessable("invalid release date, expected DD.MM.YYYY", err))
+
+		return false
+	}
+
+	return true
+}
+
+func validLanguage(w http.ResponseWriter, r *http.Request, log *slog.Logger, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	if _, err := langtag.Canonical(value); err != nil {
+		apiErr.Render(w, r, log, apiErr.Unprocessable("invalid language, expected a BCP 47 tag such as en or ru-RU", err))
+
+		return false
+	}
+
+	return true
+}
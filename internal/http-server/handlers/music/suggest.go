@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+)
+
+type Suggester interface {
+	Suggest(ctx context.Context, query models.SuggestQuery) (names []models.Name, err error)
+}
+
+type SuggestHandler struct {
+	suggester Suggester
+	log       *slog.Logger
+}
+
+func NewSuggest(suggester Suggester, log *slog.Logger) *SuggestHandler {
+	return &SuggestHandler{
+		suggester: suggester,
+		log:       log,
+	}
+}
+
+func (s *SuggestHandler) Suggest(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.Suggest"
+
+		log := s.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.SuggestQuery
+
+		// A blank query would match every name, so it is trimmed before the required check.
+		err := DecodeQuery(w, r, log, &req)
+		req.Query = strings.TrimSpace(req.Query)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		names, err := s.suggester.Suggest(ctx, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   names,
+		})
+	}
+}
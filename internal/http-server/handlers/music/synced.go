@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+	"github.com/stepan41k/Testovoe/internal/lib/lrc"
+)
+
+// maxLRCSize bounds an uploaded LRC file. Lyrics of even a long song are a few kilobytes.
+const maxLRCSize = 1 << 20
+
+// UploadLRC stores an LRC file as the synced lyrics of the song. The file is either the request
+// body itself or the "file" part of a multipart form.
+func (m *MusicHandler) UploadLRC(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.UploadLRC"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		text, err := readLRC(w, r)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				err = &apiErr.RequestError{Status: http.StatusRequestEntityTooLarge, Detail: "LRC file is larger than 1 MiB", Err: err}
+			} else {
+				err = apiErr.BadRequest("failed to read the LRC file", err)
+			}
+
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		lines, err := m.music.ImportLRC(ctx, id, text)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   lines,
+		})
+	}
+}
+
+// GetSyncedLyrics writes the synced lyrics of the song as JSON lines, an LRC file or WebVTT
+// subtitles. The format comes from the format parameter or a .lrc or .vtt extension.
+func (m *MusicHandler) GetSyncedLyrics(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetSyncedLyrics"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req models.SyncedQuery
+
+		err := DecodeQuery(w, r, log, &req)
+		if req.Format == "" {
+			extension, _ := r.Context().Value(middleware.URLFormatCtxKey).(string)
+			req.Format = models.SyncedFormat(extension)
+		}
+
+		flag := CheckForErrors(req, w, r, log, err)
+		if flag {
+			return
+		}
+
+		if req.Format == "" || req.Format == models.SyncedJSON {
+			lines, err := m.music.GetSyncedLyrics(ctx, id)
+			if err != nil {
+				apiErr.Render(w, r, log, err)
+
+				return
+			}
+
+			render.JSON(w, r, resp.Response{
+				Status: http.StatusOK,
+				Data:   lines,
+			})
+
+			return
+		}
+
+		text, err := m.music.ExportSynced(ctx, id, req.Format)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		contentType := "text/plain; charset=utf-8"
+		if req.Format == models.SyncedVTT {
+			contentType = "text/vtt; charset=utf-8"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, text)
+	}
+}
+
+// GetSyncedLine tells which line of the song is sung at the time t, given in seconds such as 83.5
+// or as an LRC timestamp such as 01:23.50.
+func (m *MusicHandler) GetSyncedLine(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetSyncedLine"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		t, err := songTime(r.URL.Query().Get("t"))
+		if err != nil {
+			apiErr.Render(w, r, log, apiErr.BadRequest("invalid time t, expected seconds such as 83.5 or mm:ss.xx", err))
+
+			return
+		}
+
+		position, err := m.music.SyncedAt(ctx, id, t)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   position,
+		})
+	}
+}
+
+func (m *MusicHandler) DeleteSyncedLyrics(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.DeleteSyncedLyrics"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		if err := m.music.DeleteSyncedLyrics(ctx, id); err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   id,
+		})
+	}
+}
+
+// readLRC reads the uploaded LRC file, from a multipart form or from the raw body.
+func readLRC(w http.ResponseWriter, r *http.Request) (string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLRCSize)
+
+	var body io.Reader = r.Body
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return "", err
+		}
+
+		defer file.Close()
+
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) == 0 {
+		return "", errors.New("empty LRC file")
+	}
+
+	return string(data), nil
+}
+
+// songTime reads a time into the song, in seconds or as an LRC timestamp.
+func songTime(value string) (time.Duration, error) {
+	if strings.Contains(value, ":") {
+		return lrc.ParseTimestamp(value)
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if seconds < 0 || math.IsNaN(seconds) || seconds > math.MaxInt64/float64(time.Second) {
+		return 0, errors.New("time out of range")
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+func (m *MusicHandler) GetTranslations(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetTranslations"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		translations, err := m.music.GetTranslations(ctx, id)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   translations,
+		})
+	}
+}
+
+func (m *MusicHandler) GetTranslation(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.GetTranslation"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		language := chi.URLParam(r, "lang")
+		if !validLanguage(w, r, log, language) {
+			return
+		}
+
+		translation, err := m.music.GetTranslation(ctx, id, language)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		w.Header().Set("Content-Language", translation.Language)
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   translation,
+		})
+	}
+}
+
+// SaveTranslation handles PUT of a translation: it is created when the song has none to the
+// language yet and replaced otherwise.
+func (m *MusicHandler) SaveTranslation(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.SaveTranslation"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		language := chi.URLParam(r, "lang")
+		if !validLanguage(w, r, log, language) {
+			return
+		}
+
+		var req models.TranslationBody
+
+		err := render.Decode(r, &req)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		if lyrics.Normalize(req.Lyrics) == "" {
+			apiErr.Render(w, r, log, apiErr.Unprocessable("translation lyrics are blank", nil))
+
+			return
+		}
+
+		translation, created, err := m.music.SaveTranslation(ctx, id, language, req.Lyrics)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+
+		render.Status(r, status)
+		render.JSON(w, r, resp.Response{
+			Status: status,
+			Data:   translation,
+		})
+	}
+}
+
+func (m *MusicHandler) DeleteTranslation(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.DeleteTranslation"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, ok := songID(w, r, log)
+		if !ok {
+			return
+		}
+
+		language := chi.URLParam(r, "lang")
+		if !validLanguage(w, r, log, language) {
+			return
+		}
+
+		if err := m.music.DeleteTranslation(ctx, id, language); err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status: http.StatusOK,
+			Data:   id,
+		})
+	}
+}
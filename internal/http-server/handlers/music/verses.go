@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+	resp "github.com/stepan41k/Testovoe/internal/lib/api/response"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+)
+
+// verseMatch links a match to the verse it was found in, both on the legacy text endpoint and on the song resource.
+type verseMatch struct {
+	models.VerseMatch
+	TextURL  string `json:"text_url"`
+	VerseURL string `json:"verse_url"`
+}
+
+func (m *MusicHandler) FindVerses(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http.handlers.music.FindVerses"
+
+		log := m.log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req models.VerseQuery
+
+		err := DecodeQuery(w, r, log, &req)
+		if CheckForErrors(req, w, r, log, err) {
+			return
+		}
+
+		if strings.TrimSpace(req.Phrase) == "" {
+			apiErr.Render(w, r, log, apiErr.Unprocessable("phrase must not be blank", nil))
+
+			return
+		}
+
+		if _, err := cursor.Decode(req.Cursor, nil); err != nil {
+			apiErr.Render(w, r, log, apiErr.BadRequest("invalid cursor, it must come from a previous response", err))
+
+			return
+		}
+
+		matches, err := m.music.FindVerses(ctx, req)
+		if err != nil {
+			apiErr.Render(w, r, log, err)
+
+			return
+		}
+
+		data := make([]verseMatch, 0, len(matches.Matches))
+		for _, match := range matches.Matches {
+			text := url.Values{
+				"band_name":  {match.BandName},
+				"song_title": {match.SongTitle},
+				"verse":      {strconv.Itoa(match.Verse)},
+			}
+
+			data = append(data, verseMatch{
+				VerseMatch: match,
+				TextURL:    "/song/text?" + text.Encode(),
+				VerseURL:   fmt.Sprintf("/v1/songs/%d/verses/%d", match.SongID, match.Verse),
+			})
+		}
+
+		render.JSON(w, r, resp.Response{
+			Status:     http.StatusOK,
+			Data:       data,
+			NextCursor: matches.NextCursor,
+		})
+	}
+}
+
+// versesText joins a page of verses into plain lyrics, one blank line between verses. Plain text has
+// no room for the pagination, so the neighbouring pages are linked in the Link header.
+func versesText(w http.ResponseWriter, r *http.Request, page models.VersePage) string {
+	if page.Page > 1 {
+		w.Header().Add("Link", "<"+pageURL(r, page.Page-1)+`>; rel="prev"`)
+	}
+
+	if page.HasNext {
+		w.Header().Add("Link", "<"+pageURL(r, page.Page+1)+`>; rel="next"`)
+	}
+
+	texts := make([]string, 0, len(page.Verses))
+	for _, verse := range page.Verses {
+		texts = append(texts, verse.Text)
+	}
+
+	return strings.Join(texts, "\n\n")
+}
+
+// pageURL is the URL of the request with its page parameter set to page.
+func pageURL(r *http.Request, page int) string {
+	values := r.URL.Query()
+	values.Set("page", strconv.Itoa(page))
+
+	return (&url.URL{Path: r.URL.Path, RawQuery: values.Encode()}).String()
+}
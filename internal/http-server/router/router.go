@@ -0,0 +1,112 @@
+// Package router wires the HTTP handlers to their routes.
+package router
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	adminHandler "github.com/stepan41k/Testovoe/internal/http-server/handlers/admin"
+	musicHandler "github.com/stepan41k/Testovoe/internal/http-server/handlers/music"
+	apiErr "github.com/stepan41k/Testovoe/internal/lib/api/error"
+)
+
+// New builds the router. The /admin routes require adminToken as a bearer token and are left out
+// when it is empty.
+func New(music *musicHandler.MusicHandler, suggest *musicHandler.SuggestHandler, admin *adminHandler.AdminHandler, adminToken string, log *slog.Logger) chi.Router {
+	router := chi.NewRouter()
+
+	router.Use(middleware.RequestID)
+	router.Use(middleware.Recoverer)
+	router.Use(middleware.URLFormat)
+
+	router.Route("/v1", func(r chi.Router) {
+		r.Route("/songs", func(r chi.Router) {
+			r.Get("/", music.GetSongs(context.Background()))
+			r.Post("/", music.CreateSong(context.Background()))
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", music.GetSong(context.Background()))
+				r.Put("/", music.ReplaceSong(context.Background()))
+				r.Patch("/", music.PatchSong(context.Background()))
+				r.Delete("/", music.DeleteSongByID(context.Background()))
+				r.Get("/lyrics", music.GetLyrics(context.Background()))
+				r.Get("/lyrics/synced", music.GetSyncedLyrics(context.Background()))
+				r.Put("/lyrics/synced", music.UploadLRC(context.Background()))
+				r.Delete("/lyrics/synced", music.DeleteSyncedLyrics(context.Background()))
+				r.Get("/lyrics/synced/at", music.GetSyncedLine(context.Background()))
+				r.Get("/translations", music.GetTranslations(context.Background()))
+				r.Get("/translations/{lang}", music.GetTranslation(context.Background()))
+				r.Put("/translations/{lang}", music.SaveTranslation(context.Background()))
+				r.Delete("/translations/{lang}", music.DeleteTranslation(context.Background()))
+				r.Get("/verses/{n}", music.GetVerse(context.Background()))
+				r.Get("/structure", music.GetStructure(context.Background()))
+			})
+		})
+
+		r.Get("/verses", music.FindVerses(context.Background()))
+		r.Get("/suggest", suggest.Suggest(context.Background()))
+	})
+
+	router.Mount("/song", Legacy(music))
+
+	if adminToken == "" {
+		log.Warn("admin token is not set, admin routes are disabled")
+	} else {
+		router.Route("/admin", func(r chi.Router) {
+			r.Use(bearer(adminToken, log))
+
+			r.Post("/enrichment/requeue", admin.RequeueFailedJobs(context.Background()))
+		})
+	}
+
+	return router
+}
+
+// Legacy serves the original /song routes that address songs by band and title. They are kept
+// for existing clients and point to the /v1/songs resource as their successor.
+func Legacy(music *musicHandler.MusicHandler) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(deprecated("/v1/songs"))
+
+	r.Get("/songs", music.GetSongs(context.Background()))
+	r.Get("/text", music.GetTextSong(context.Background()))
+	r.Delete("/delete", music.DeleteSong(context.Background()))
+	r.Put("/update", music.UpdateSong(context.Background()))
+	r.Post("/new", music.AddNewSong(context.Background()))
+
+	return r
+}
+
+func deprecated(successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearer lets through only requests that carry token in an "Authorization: Bearer" header.
+func bearer(token string, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				apiErr.Render(w, r, log, &apiErr.RequestError{Status: http.StatusUnauthorized, Detail: "a valid admin bearer token is required"})
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package infomock
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Fixture struct {
+	Latency   time.Duration `yaml:"latency" json:"latency"`
+	ErrorRate float64       `yaml:"error_rate" json:"error_rate"`
+	Seed      int64         `yaml:"seed" json:"seed"`
+	Songs     []Song        `yaml:"songs" json:"songs"`
+}
+
+type Song struct {
+	Group       string        `yaml:"group" json:"group"`
+	Song        string        `yaml:"song" json:"song"`
+	ReleaseDate string        `yaml:"releaseDate" json:"releaseDate"`
+	Text        string        `yaml:"text" json:"text"`
+	Link        string        `yaml:"link" json:"link"`
+	Latency     time.Duration `yaml:"latency" json:"latency"`
+	ErrorRate   *float64      `yaml:"error_rate" json:"error_rate"`
+	Status      int           `yaml:"status" json:"status"`
+}
+
+type songDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+type key struct {
+	group string
+	song  string
+}
+
+type Server struct {
+	fixture Fixture
+	songs   map[key]Song
+	log     *slog.Logger
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// LoadFixture reads a fixture file. YAML is a superset of JSON, so both formats are accepted.
+func LoadFixture(path string) (Fixture, error) {
+	const op = "infomock.LoadFixture"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var fixture Fixture
+
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return Fixture{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fixture, nil
+}
+
+func New(fixture Fixture, log *slog.Logger) *Server {
+	songs := make(map[key]Song, len(fixture.Songs))
+	for _, song := range fixture.Songs {
+		songs[key{group: song.Group, song: song.Song}] = song
+	}
+
+	seed := fixture.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Server{
+		fixture: fixture,
+		songs:   songs,
+		log:     log,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const op = "infomock.ServeHTTP"
+
+	if r.Method != http.MethodGet || r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+
+	group, title := r.URL.Query().Get("group"), r.URL.Query().Get("song")
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("group", group),
+		slog.String("song", title),
+	)
+
+	if group == "" || title == "" {
+		log.Debug("bad request")
+
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	song, found := s.songs[key{group: group, song: title}]
+
+	latency, errorRate := s.fixture.Latency, s.fixture.ErrorRate
+	if song.Latency > 0 {
+		latency = song.Latency
+	}
+	if song.ErrorRate != nil {
+		errorRate = *song.ErrorRate
+	}
+
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+
+	switch {
+	case s.fail(errorRate):
+		log.Debug("injected error")
+
+		w.WriteHeader(http.StatusInternalServerError)
+	case !found:
+		log.Debug("song not found")
+
+		w.WriteHeader(http.StatusNotFound)
+	case song.Status != 0 && song.Status != http.StatusOK:
+		log.Debug("forced status", slog.Int("status", song.Status))
+
+		w.WriteHeader(song.Status)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+
+		_ = json.NewEncoder(w).Encode(songDetail{
+			ReleaseDate: song.ReleaseDate,
+			Text:        song.Text,
+			Link:        song.Link,
+		})
+	}
+}
+
+func (s *Server) fail(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rand.Float64() < rate
+}
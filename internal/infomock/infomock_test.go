@@ -0,0 +1,163 @@
+package infomock
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServeHTTP(t *testing.T) {
+	server := New(Fixture{
+		Songs: []Song{
+			{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com"},
+			{Group: "Muse", Song: "Hysteria", Status: http.StatusServiceUnavailable},
+			{Group: "Muse", Song: "Uprising", Status: http.StatusOK, ReleaseDate: "07.09.2009"},
+		},
+	}, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+		want   songDetail
+	}{
+		{name: "found", target: info("Muse", "Starlight"), status: http.StatusOK, want: songDetail{ReleaseDate: "03.09.2006", Text: "Far away", Link: "https://example.com"}},
+		{name: "forced status", target: info("Muse", "Hysteria"), status: http.StatusServiceUnavailable},
+		{name: "forced ok", target: info("Muse", "Uprising"), status: http.StatusOK, want: songDetail{ReleaseDate: "07.09.2009"}},
+		{name: "unknown song", target: info("Muse", "Unknown"), status: http.StatusNotFound},
+		{name: "no song", target: "/info?group=Muse", status: http.StatusBadRequest},
+		{name: "other path", target: "/songs", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status: got %d, want %d", rec.Code, tt.status)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got songDetail
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got != tt.want {
+				t.Fatalf("body: got %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatency(t *testing.T) {
+	server := New(Fixture{
+		Latency: 50 * time.Millisecond,
+		Songs: []Song{
+			{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006"},
+			{Group: "Muse", Song: "Hysteria", ReleaseDate: "01.12.2003", Latency: 150 * time.Millisecond},
+		},
+	}, slog.New(slog.DiscardHandler))
+
+	for song, want := range map[string]time.Duration{"Starlight": 50 * time.Millisecond, "Hysteria": 150 * time.Millisecond} {
+		start := time.Now()
+
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, info("Muse", song), nil))
+
+		if elapsed := time.Since(start); elapsed < want || rec.Code != http.StatusOK {
+			t.Fatalf("%s: got %d after %v, want 200 after at least %v", song, rec.Code, elapsed, want)
+		}
+	}
+
+	// A client that gives up is not kept waiting for the rest of the latency.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, info("Muse", "Hysteria"), nil).WithContext(ctx))
+
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Fatalf("canceled request: answered after %v, want it dropped", elapsed)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	never := 0.0
+
+	server := New(Fixture{
+		ErrorRate: 0.5,
+		Seed:      1,
+		Songs: []Song{
+			{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006"},
+			{Group: "Muse", Song: "Hysteria", ReleaseDate: "01.12.2003", ErrorRate: &never},
+		},
+	}, slog.New(slog.DiscardHandler))
+
+	const requests = 400
+
+	failures := map[string]int{}
+	for _, song := range []string{"Starlight", "Hysteria"} {
+		for range requests {
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, info("Muse", song), nil))
+
+			if rec.Code == http.StatusInternalServerError {
+				failures[song]++
+			}
+		}
+	}
+
+	if got := failures["Starlight"]; got < requests/4 || got > requests*3/4 {
+		t.Fatalf("error rate 0.5: got %d failures out of %d", got, requests)
+	}
+
+	if got := failures["Hysteria"]; got != 0 {
+		t.Fatalf("song error rate 0: got %d failures, want none", got)
+	}
+}
+
+func TestLoadFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.yaml")
+
+	err := os.WriteFile(path, []byte(`
+latency: 20ms
+error_rate: 0.1
+songs:
+  - group: "Muse"
+    song: "Hysteria"
+    status: 503
+    error_rate: 0
+`), 0o600)
+	if err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture: %v", err)
+	}
+
+	if fixture.Latency != 20*time.Millisecond || fixture.ErrorRate != 0.1 || len(fixture.Songs) != 1 {
+		t.Fatalf("LoadFixture: got %+v", fixture)
+	}
+
+	if song := fixture.Songs[0]; song.Status != http.StatusServiceUnavailable || song.ErrorRate == nil || *song.ErrorRate != 0 {
+		t.Fatalf("LoadFixture song: got %+v", song)
+	}
+
+	if _, err := LoadFixture(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("LoadFixture of a missing file: got no error")
+	}
+}
+
+func info(group, song string) string {
+	return "/info?" + url.Values{"group": {group}, "song": {song}}.Encode()
+}
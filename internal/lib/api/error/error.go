@@ -1,3 +1,180 @@
+// Package error maps errors from every layer to HTTP status codes and writes them as
+// RFC 7807 problem details.
 package error
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Testovoe/internal/lib/api/query"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/lrc"
+)
+
+const ContentType = "application/problem+json"
+
+var ErrDecode = errors.New("failed to decode request")
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type      string   `json:"type"`
+	Title     string   `json:"title"`
+	Status    int      `json:"status"`
+	Detail    string   `json:"detail,omitempty"`
+	Instance  string   `json:"instance,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// RequestError is a client error whose status and detail are decided by the handler.
+type RequestError struct {
+	Status int
+	Detail string
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err == nil {
+		return e.Detail
+	}
+
+	return e.Detail + ": " + e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// BadRequest reports a request that could not be read, such as a malformed parameter.
+func BadRequest(detail string, err error) error {
+	return &RequestError{Status: http.StatusBadRequest, Detail: detail, Err: err}
+}
+
+// Unprocessable reports a well-formed request whose values are not valid.
+func Unprocessable(detail string, err error) error {
+	return &RequestError{Status: http.StatusUnprocessableEntity, Detail: detail, Err: err}
+}
+
+// Decode marks an error returned while decoding a request body.
+func Decode(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: empty request body", ErrDecode)
+	}
+
+	return fmt.Errorf("%w: %w", ErrDecode, err)
+}
+
+// Map translates an error into a problem. Unknown errors become an internal error that does not
+// leak the cause to the client.
+func Map(err error) Problem {
+	var requestErr *RequestError
+	var validateErr validator.ValidationErrors
+	var paramErr *query.ParamError
+	var lrcErr *lrc.SyntaxError
+
+	switch {
+	case errors.As(err, &requestErr):
+		return problem(requestErr.Status, requestErr.Detail)
+	case errors.As(err, &validateErr):
+		p := problem(http.StatusUnprocessableEntity, "request validation failed")
+		p.Errors = validationMessages(validateErr)
+
+		return p
+	case errors.As(err, &paramErr):
+		return problem(http.StatusBadRequest, paramErr.Error())
+	case errors.Is(err, ErrDecode):
+		return problem(http.StatusBadRequest, err.Error())
+	case errors.As(err, &lrcErr):
+		return problem(http.StatusUnprocessableEntity, lrcErr.Error())
+	case errors.Is(err, lrc.ErrMalformed):
+		return problem(http.StatusUnprocessableEntity, lrc.ErrMalformed.Error())
+	case errors.Is(err, lrc.ErrNoLines):
+		return problem(http.StatusUnprocessableEntity, lrc.ErrNoLines.Error())
+	case errors.Is(err, langtag.ErrInvalid):
+		return problem(http.StatusUnprocessableEntity, "invalid language, expected a BCP 47 tag such as en or ru-RU")
+	case errors.Is(err, errs.ErrSongNotFound):
+		return problem(http.StatusNotFound, songDetail(err, "not found"))
+	case errors.Is(err, errs.ErrSongExists):
+		return problem(http.StatusConflict, songDetail(err, "already exists"))
+	case errors.Is(err, errs.ErrInvalidSong):
+		return problem(http.StatusUnprocessableEntity, "song data is not valid")
+	case errors.Is(err, errs.ErrNoSyncedLyrics):
+		return problem(http.StatusNotFound, songDetail(err, "has no synced lyrics"))
+	case errors.Is(err, errs.ErrNoTranslation):
+		return problem(http.StatusNotFound, songDetail(err, "has no translation to this language"))
+	case errors.Is(err, errs.ErrNoChanges):
+		return problem(http.StatusBadRequest, "nothing to change")
+	default:
+		return problem(http.StatusInternalServerError, "internal error")
+	}
+}
+
+// Render maps err, logs it and writes the problem response.
+func Render(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	p := Map(err)
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetReqID(r.Context())
+
+	if p.Status >= http.StatusInternalServerError {
+		log.Error("internal error", sl.Err(err))
+	} else {
+		log.Warn("request failed", slog.Int("status", p.Status), sl.Err(err))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// songDetail names the song an error is about, without leaking the underlying cause.
+func songDetail(err error, what string) string {
+	var songErr *errs.SongError
+
+	switch {
+	case !errors.As(err, &songErr):
+		return "song " + what
+	case songErr.ID != 0:
+		return fmt.Sprintf("song %d %s", songErr.ID, what)
+	default:
+		return fmt.Sprintf("song %q by %q %s", songErr.SongTitle, songErr.BandName, what)
+	}
+}
+
+func problem(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func validationMessages(errs validator.ValidationErrors) []string {
+	var msgs []string
+
+	for _, err := range errs {
+		switch err.ActualTag() {
+		case "required":
+			msgs = append(msgs, fmt.Sprintf("field %s is a required field", err.Field()))
+		case "min":
+			msgs = append(msgs, fmt.Sprintf("field %s must be at least %s", err.Field(), err.Param()))
+		case "max":
+			msgs = append(msgs, fmt.Sprintf("field %s must be at most %s", err.Field(), err.Param()))
+		case "excluded_with":
+			msgs = append(msgs, fmt.Sprintf("field %s cannot be combined with %s", err.Field(), err.Param()))
+		default:
+			msgs = append(msgs, fmt.Sprintf("field %s is not valid", err.Field()))
+		}
+	}
+
+	return msgs
+}
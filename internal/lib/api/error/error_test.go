@@ -0,0 +1,50 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/lib/api/query"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/lrc"
+)
+
+func TestMap(t *testing.T) {
+	_, langErr := langtag.Canonical("en_")
+	_, lrcErr := lrc.Parse("far away")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{name: "request error", err: Unprocessable("phrase must not be blank", nil), status: http.StatusUnprocessableEntity, detail: "phrase must not be blank"},
+		{name: "param error", err: &query.ParamError{Param: "page", Value: "abc"}, status: http.StatusBadRequest},
+		{name: "decode error", err: Decode(errors.New("unexpected EOF")), status: http.StatusBadRequest},
+		{name: "language", err: fmt.Errorf("service: %w", langErr), status: http.StatusUnprocessableEntity, detail: "invalid language, expected a BCP 47 tag such as en or ru-RU"},
+		{name: "lrc syntax", err: fmt.Errorf("service: %w", lrcErr), status: http.StatusUnprocessableEntity, detail: lrcErr.Error()},
+		{name: "no lrc lines", err: lrc.ErrNoLines, status: http.StatusUnprocessableEntity},
+		{name: "song not found", err: errs.ByID(7, errs.ErrSongNotFound), status: http.StatusNotFound, detail: "song 7 not found"},
+		{name: "song exists", err: errs.ByName("Muse", "Starlight", errs.ErrSongExists), status: http.StatusConflict, detail: `song "Starlight" by "Muse" already exists`},
+		{name: "invalid song", err: errs.ErrInvalidSong, status: http.StatusUnprocessableEntity},
+		{name: "no changes", err: errs.ErrNoChanges, status: http.StatusBadRequest},
+		{name: "unknown", err: errors.New("connection refused"), status: http.StatusInternalServerError, detail: "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Map(tt.err)
+			if got.Status != tt.status || got.Title != http.StatusText(tt.status) {
+				t.Fatalf("Map(%v): got %d %q, want %d", tt.err, got.Status, got.Title, tt.status)
+			}
+
+			if tt.detail != "" && got.Detail != tt.detail {
+				t.Fatalf("Map(%v): got detail %q, want %q", tt.err, got.Detail, tt.detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Package query decodes URL query parameters into request structs, using the json tags as parameter names.
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParamError reports a query parameter whose value does not fit the field type.
+type ParamError struct {
+	Param string
+	Value string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid value %q for query parameter %s", e.Value, e.Param)
+}
+
+// Decode fills the fields of the struct dst points at from values. Parameters without a matching
+// field are ignored, fields without a parameter keep their value. Supported kinds are strings,
+// integers, booleans and pointers to them.
+func Decode(values url.Values, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("lib.api.query.Decode: destination must be a pointer to a struct, got %T", dst)
+	}
+
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		raw, ok := values[name]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+
+		if err := set(v.Field(i), raw[len(raw)-1]); err != nil {
+			return &ParamError{Param: name, Value: raw[len(raw)-1]}
+		}
+	}
+
+	return nil
+}
+
+func set(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Pointer {
+		value := reflect.New(field.Type().Elem())
+		if err := set(value.Elem(), raw); err != nil {
+			return err
+		}
+
+		field.Set(value)
+
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		// A bare flag such as ?count means true.
+		if raw == "" {
+			field.SetBool(true)
+			return nil
+		}
+
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(value)
+	default:
+		return fmt.Errorf("unsupported kind %s", field.Kind())
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+type request struct {
+	Band     string  `json:"band_name,omitempty"`
+	Page     int     `json:"page"`
+	Small    int8    `json:"small"`
+	Count    bool    `json:"count"`
+	HasLink  *bool   `json:"has_link"`
+	Score    float64 `json:"score"`
+	Skipped  string  `json:"-"`
+	Untagged string
+	Tags     []string `json:"tags"`
+}
+
+func TestDecode(t *testing.T) {
+	values, _ := url.ParseQuery("band_name=Muse&page=2&small=-3&count&has_link=false&score=0.5&Untagged=x&colour=red&-=y")
+
+	var got request
+	if err := Decode(values, &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got.Band != "Muse" || got.Page != 2 || got.Small != -3 || !got.Count || got.HasLink == nil || *got.HasLink || got.Score != 0.5 {
+		t.Fatalf("Decode: got %+v", got)
+	}
+
+	if got.Skipped != "" || got.Untagged != "" {
+		t.Fatalf("Decode of fields without a parameter name: got %+v, want them left alone", got)
+	}
+
+	kept := request{Band: "Placebo", Page: 7}
+	if err := Decode(url.Values{"page": {"1", "3"}, "band_name": {}}, &kept); err != nil || kept.Page != 3 || kept.Band != "Placebo" || kept.HasLink != nil {
+		t.Fatalf("Decode of repeated and empty parameters: got %+v, %v, want the last value and the rest kept", kept, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		param string
+	}{
+		{name: "bad int", query: "page=two", param: "page"},
+		{name: "fractional int", query: "page=1.5", param: "page"},
+		{name: "int overflow", query: "small=200", param: "small"},
+		{name: "huge int", query: "page=99999999999999999999", param: "page"},
+		{name: "bad bool", query: "count=maybe", param: "count"},
+		{name: "bad pointer", query: "has_link=nope", param: "has_link"},
+		{name: "bad float", query: "score=high", param: "score"},
+		{name: "unsupported kind", query: "tags=a", param: "tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+
+			var paramErr *ParamError
+			if err := Decode(values, &request{}); !errors.As(err, &paramErr) || paramErr.Param != tt.param {
+				t.Fatalf("Decode(%q): got %v, want a ParamError for %s", tt.query, err, tt.param)
+			}
+		})
+	}
+
+	var notStruct int
+	if err := Decode(url.Values{}, &notStruct); err == nil {
+		t.Fatalf("Decode into an int: got no error")
+	}
+
+	if err := Decode(url.Values{}, request{}); err == nil {
+		t.Fatalf("Decode into a struct value: got no error")
+	}
+}
@@ -1,33 +1,28 @@
 package response
 
-import (
-	"fmt"
-	"net/http"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
-)
+import "github.com/stepan41k/Testovoe/internal/domain/models"
 
 type Response struct {
 	Status int`json:"status"`
-	Error  string `json:"error,omitempty"`
 	Data   any `json:"data,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	DidYouMean *models.DidYouMean `json:"did_you_mean,omitempty"`
+	Verse *VersePosition `json:"verse,omitempty"`
 }
 
-func ValidationError(errs validator.ValidationErrors) Response {
-	var errMsgs []string
-
-	for _, err := range errs {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
-		}
-	}
+type Pagination struct {
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	HasNext  bool   `json:"has_next"`
+	Total    *int64 `json:"total,omitempty"`
+}
 
-	return Response{
-		Status: http.StatusBadRequest,
-		Error:  strings.Join(errMsgs, ", "),
-	}
-}
\ No newline at end of file
+type VersePosition struct {
+	Number int    `json:"number"`
+	Kind   string `json:"kind"`
+	Total  int    `json:"total"`
+	Prev   int    `json:"prev,omitempty"`
+	Next   int    `json:"next,omitempty"`
+}
@@ -0,0 +1,135 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the boundary row of a page: the values of the active sort keys plus the id tiebreaker.
+// A backward cursor asks for the rows before the boundary.
+type Cursor struct {
+	Sort     string   `json:"s"`
+	Values   []string `json:"v"`
+	ID       int64    `json:"i"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+func Encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses an opaque cursor issued for the given sort keys. An empty token yields a nil cursor.
+func Decode(token string, keys []sorting.Key) (*Cursor, error) {
+	const op = "lib.cursor.Decode"
+
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCursor)
+	}
+
+	var c Cursor
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCursor)
+	}
+
+	if c.Sort != sorting.Format(keys) || len(c.Values) != len(keys) {
+		return nil, fmt.Errorf("%s: %w: cursor was issued for another sort order", op, ErrInvalidCursor)
+	}
+
+	for i, key := range keys {
+		if !validValue(key.Field, c.Values[i]) {
+			return nil, fmt.Errorf("%s: %w: invalid %s value %q", op, ErrInvalidCursor, key.Field, c.Values[i])
+		}
+	}
+
+	return &c, nil
+}
+
+// validValue reports whether value has the type of the sort field, so that a tampered cursor is
+// rejected here instead of failing a cast in the database. Each backend writes dates, times and
+// ranks a little differently, and every one of those forms is accepted.
+func validValue(field sorting.Field, value string) bool {
+	if strings.ContainsRune(value, 0) {
+		return false
+	}
+
+	switch field {
+	case sorting.FieldRelease:
+		_, err := time.Parse("2006-01-02", value)
+
+		return value == "" || err == nil
+	case sorting.FieldUpdated:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999"} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return true
+			}
+		}
+
+		return false
+	case sorting.FieldRank:
+		rank, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+
+		// Ranks are stored as real, which holds neither infinities nor values below its smallest.
+		rank = math.Abs(rank)
+
+		return rank == 0 || rank >= math.SmallestNonzeroFloat32 && rank <= math.MaxFloat32
+	default:
+		return true
+	}
+}
+
+// Paginate turns rows fetched with one row of look-ahead into a page and the cursors around it.
+// Rows fetched for a backward cursor come in reverse order and are flipped back here.
+// offset tells whether the page was reached by page number rather than from the start.
+func Paginate[T any](rows []T, limit int, request *Cursor, offset int, keys []sorting.Key, boundary func(T) ([]string, int64)) (page []T, next, prev string) {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	backward := request != nil && request.Backward
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	encode := func(row T, backward bool) string {
+		values, id := boundary(row)
+
+		return Encode(Cursor{Sort: sorting.Format(keys), Values: values, ID: id, Backward: backward})
+	}
+
+	if more || backward {
+		next = encode(rows[len(rows)-1], false)
+	}
+
+	if (backward && more) || (!backward && (request != nil || offset > 0)) {
+		prev = encode(rows[0], true)
+	}
+
+	return rows, next, prev
+}
@@ -0,0 +1,125 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+func TestDecode(t *testing.T) {
+	keys := []sorting.Key{{Field: sorting.FieldRelease, Desc: true}, {Field: sorting.FieldBand}}
+
+	valid := Cursor{Sort: "-release_date,band_name", Values: []string{"2006-09-03", "Muse"}, ID: 7}
+
+	got, err := Decode(Encode(valid), keys)
+	if err != nil || !reflect.DeepEqual(*got, valid) {
+		t.Fatalf("Decode of an issued cursor: got %+v, %v, want %+v", got, err, valid)
+	}
+
+	if got, err := Decode("", keys); got != nil || err != nil {
+		t.Fatalf("Decode of no cursor: got %+v, %v, want nil", got, err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		keys  []sorting.Key
+	}{
+		{name: "bad base64", token: "not a cursor!", keys: keys},
+		{name: "padded base64", token: base64.URLEncoding.EncodeToString([]byte(`{"s":""}`)), keys: nil},
+		{name: "not json", token: base64.RawURLEncoding.EncodeToString([]byte("cursor")), keys: keys},
+		{name: "wrong value type", token: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"band_name","v":[1],"i":1}`)), keys: []sorting.Key{{Field: sorting.FieldBand}}},
+		{name: "other sort", token: Encode(Cursor{Sort: "band_name", Values: []string{"Muse"}}), keys: keys},
+		{name: "other direction", token: Encode(Cursor{Sort: "release_date,band_name", Values: valid.Values}), keys: keys},
+		{name: "too few values", token: Encode(Cursor{Sort: valid.Sort, Values: []string{"2006-09-03"}}), keys: keys},
+		{name: "too many values", token: Encode(Cursor{Sort: valid.Sort, Values: []string{"2006-09-03", "Muse", "Starlight"}}), keys: keys},
+		{name: "values without sort", token: Encode(Cursor{Values: []string{"Muse"}}), keys: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Decode(tt.token, tt.keys); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("Decode: got %+v, %v, want ErrInvalidCursor", got, err)
+			}
+		})
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	tests := []struct {
+		field sorting.Field
+		value string
+		valid bool
+	}{
+		{sorting.FieldBand, "Muse", true},
+		{sorting.FieldBand, "", true},
+		{sorting.FieldBand, "Mu\x00se", false},
+		{sorting.FieldRelease, "2006-09-03", true},
+		{sorting.FieldRelease, "", true},
+		{sorting.FieldRelease, "03.09.2006", false},
+		{sorting.FieldRelease, "2006-13-01", false},
+		{sorting.FieldRelease, "2006-09-03'; DROP TABLE songs; --", false},
+		{sorting.FieldUpdated, "2024-05-01T10:20:30.123456Z", true},
+		{sorting.FieldUpdated, "2024-05-01T10:20:30+03:00", true},
+		{sorting.FieldUpdated, "2024-05-01 10:20:30.123", true},
+		{sorting.FieldUpdated, "2024-05-01", false},
+		{sorting.FieldUpdated, "yesterday", false},
+		{sorting.FieldRank, "0", true},
+		{sorting.FieldRank, "0.0607927", true},
+		{sorting.FieldRank, "-1.5", true},
+		{sorting.FieldRank, "1e-50", false},
+		{sorting.FieldRank, "1e50", false},
+		{sorting.FieldRank, "NaN", false},
+		{sorting.FieldRank, "Inf", false},
+		{sorting.FieldRank, "high", false},
+	}
+
+	for _, tt := range tests {
+		keys := []sorting.Key{{Field: tt.field}}
+		token := Encode(Cursor{Sort: sorting.Format(keys), Values: []string{tt.value}, ID: 1})
+
+		_, err := Decode(token, keys)
+		if valid := err == nil; valid != tt.valid {
+			t.Fatalf("Decode of %s %q: got %v, want valid %v", tt.field, tt.value, err, tt.valid)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	keys := []sorting.Key{{Field: sorting.FieldBand}}
+	boundary := func(row string) ([]string, int64) { return []string{row}, int64(len(row)) }
+
+	decode := func(token string) *Cursor {
+		t.Helper()
+
+		c, err := Decode(token, keys)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+
+		return c
+	}
+
+	page, next, prev := Paginate([]string{"a", "bb", "ccc"}, 2, nil, 0, keys, boundary)
+	if !reflect.DeepEqual(page, []string{"a", "bb"}) || prev != "" || decode(next).ID != 2 || decode(next).Backward {
+		t.Fatalf("first page: got %v, next %+v, prev %q", page, decode(next), prev)
+	}
+
+	page, next, prev = Paginate([]string{"ccc"}, 2, decode(next), 0, keys, boundary)
+	if !reflect.DeepEqual(page, []string{"ccc"}) || next != "" || !decode(prev).Backward || decode(prev).ID != 3 {
+		t.Fatalf("last page: got %v, next %q, prev %+v", page, next, decode(prev))
+	}
+
+	// Rows before a backward cursor come newest first and are flipped back.
+	page, next, prev = Paginate([]string{"bb", "a"}, 2, decode(prev), 0, keys, boundary)
+	if !reflect.DeepEqual(page, []string{"a", "bb"}) || prev != "" || decode(next).ID != 2 {
+		t.Fatalf("page before: got %v, next %+v, prev %q", page, decode(next), prev)
+	}
+
+	if page, next, prev = Paginate([]string{}, 2, nil, 0, keys, boundary); len(page) != 0 || next != "" || prev != "" {
+		t.Fatalf("empty page: got %v, next %q, prev %q", page, next, prev)
+	}
+}
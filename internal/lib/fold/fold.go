@@ -0,0 +1,22 @@
+// Package fold reduces names to a form that compares case- and accent-insensitively.
+package fold
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// String lowercases s and strips its combining marks, so "Beyoncé" and "BEYONCE" fold to the same
+// string. Letters like й and ё lose their marks as well.
+func String(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		folded = s
+	}
+
+	return strings.ToLower(folded)
+}
@@ -0,0 +1,286 @@
+// Package fulltext is an in-process stand-in for Postgres text search, used by the backends that
+// have no tsvector support. Text is split like the "simple" configuration does it: lowercased runs
+// of letters and digits, without stemming or stop words.
+package fulltext
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+type Mode string
+
+const (
+	// ModeWebsearch follows websearch_to_tsquery: "quoted phrases", OR between alternatives
+	// and a leading - to exclude a word.
+	ModeWebsearch Mode = "websearch"
+	// ModePhrase follows phraseto_tsquery: the words have to appear in this order, next to each other.
+	ModePhrase Mode = "phrase"
+	// ModePlain follows plainto_tsquery: every word has to appear somewhere.
+	ModePlain Mode = "plain"
+)
+
+const (
+	headlineWords   = 35
+	headlineContext = 5
+	startSel        = "<b>"
+	stopSel         = "</b>"
+)
+
+type token struct {
+	word       string
+	start, end int
+}
+
+// term is a word or a phrase that has to be present, or absent when negated.
+type term struct {
+	words  []string
+	negate bool
+}
+
+// Query is a parsed search: it matches when all terms of any one group match.
+type Query struct {
+	groups [][]term
+}
+
+func Parse(mode Mode, text string) Query {
+	switch mode {
+	case ModePhrase:
+		if words := words(text); len(words) > 0 {
+			return Query{groups: [][]term{{{words: words}}}}
+		}
+
+		return Query{}
+	case ModePlain:
+		var group []term
+		for _, word := range words(text) {
+			group = append(group, term{words: []string{word}})
+		}
+
+		if len(group) == 0 {
+			return Query{}
+		}
+
+		return Query{groups: [][]term{group}}
+	default:
+		return parseWebsearch(text)
+	}
+}
+
+func parseWebsearch(text string) Query {
+	var groups [][]term
+	var current []term
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negate := runes[i] == '-'
+		if negate {
+			i++
+		}
+
+		var chunk string
+		if i < len(runes) && runes[i] == '"' {
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+
+			chunk = string(runes[i+1 : min(j, len(runes))])
+			i = j + 1
+		} else {
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '"' {
+				j++
+			}
+
+			chunk = string(runes[i:j])
+			i = j
+
+			if !negate && strings.EqualFold(chunk, "or") {
+				if len(current) > 0 {
+					groups = append(groups, current)
+					current = nil
+				}
+
+				continue
+			}
+		}
+
+		if words := words(chunk); len(words) > 0 {
+			current = append(current, term{words: words, negate: negate})
+		}
+	}
+
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+
+	return Query{groups: groups}
+}
+
+func (q Query) Empty() bool {
+	return len(q.groups) == 0
+}
+
+func (q Query) Match(text string) bool {
+	words := words(text)
+
+	for _, group := range q.groups {
+		if matchGroup(group, words) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rank scores a matching text by the number of hits, damped by its length like ts_rank with
+// length normalization. Texts that do not match score zero.
+func (q Query) Rank(text string) float64 {
+	words := words(text)
+
+	hits := 0
+	for _, group := range q.groups {
+		if !matchGroup(group, words) {
+			continue
+		}
+
+		for _, t := range group {
+			if !t.negate {
+				hits += len(occurrences(words, t.words))
+			}
+		}
+	}
+
+	if hits == 0 {
+		return 0
+	}
+
+	return float64(hits) / math.Log2(float64(len(words))+2)
+}
+
+// Headline returns a fragment of text around the first hit with every hit wrapped in <b></b>,
+// like ts_headline with its default options.
+func (q Query) Headline(text string) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+
+	hit := make([]bool, len(tokens))
+	first := -1
+
+	for _, group := range q.groups {
+		for _, t := range group {
+			if t.negate {
+				continue
+			}
+
+			for _, at := range occurrences(words, t.words) {
+				for k := range t.words {
+					hit[at+k] = true
+				}
+
+				if first == -1 || at < first {
+					first = at
+				}
+			}
+		}
+	}
+
+	from := max(0, first-headlineContext)
+	to := min(len(tokens), from+headlineWords)
+
+	var b strings.Builder
+
+	pos := tokens[from].start
+	for i := from; i < to; i++ {
+		b.WriteString(text[pos:tokens[i].start])
+
+		if hit[i] {
+			b.WriteString(startSel + text[tokens[i].start:tokens[i].end] + stopSel)
+		} else {
+			b.WriteString(text[tokens[i].start:tokens[i].end])
+		}
+
+		pos = tokens[i].end
+	}
+
+	return b.String()
+}
+
+func matchGroup(group []term, words []string) bool {
+	for _, t := range group {
+		if found := len(occurrences(words, t.words)) > 0; found == t.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// occurrences returns every position where phrase starts in words.
+func occurrences(words, phrase []string) []int {
+	var positions []int
+
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for k, word := range phrase {
+			if words[i+k] != word {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			positions = append(positions, i)
+		}
+	}
+
+	return positions
+}
+
+func words(text string) []string {
+	tokens := tokenize(text)
+
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+
+	return words
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		switch {
+		case inWord && start == -1:
+			start = i
+		case !inWord && start != -1:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start != -1 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
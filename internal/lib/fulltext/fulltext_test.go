@@ -0,0 +1,133 @@
+package fulltext
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const lyrics = "Far away, this ship is taking me far away. Far away from the memories"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		query string
+		text  string
+		want  bool
+	}{
+		{name: "word", mode: ModeWebsearch, query: "SHIP", text: lyrics, want: true},
+		{name: "missing word", mode: ModeWebsearch, query: "home", text: lyrics},
+		{name: "all words", mode: ModeWebsearch, query: "far memories", text: lyrics, want: true},
+		{name: "one word missing", mode: ModeWebsearch, query: "far home", text: lyrics},
+		{name: "quoted phrase", mode: ModeWebsearch, query: `"taking me far"`, text: lyrics, want: true},
+		{name: "quoted phrase out of order", mode: ModeWebsearch, query: `"me taking"`, text: lyrics},
+		{name: "unclosed quote", mode: ModeWebsearch, query: `"this ship`, text: lyrics, want: true},
+		{name: "punctuation inside a phrase", mode: ModeWebsearch, query: `"away this"`, text: lyrics, want: true},
+		{name: "negation", mode: ModeWebsearch, query: "far -ship", text: lyrics},
+		{name: "negation of a missing word", mode: ModeWebsearch, query: "far -home", text: lyrics, want: true},
+		{name: "negated phrase", mode: ModeWebsearch, query: `far -"ship is"`, text: lyrics},
+		{name: "negation only", mode: ModeWebsearch, query: "-home", text: lyrics, want: true},
+		{name: "or", mode: ModeWebsearch, query: "home or ship", text: lyrics, want: true},
+		{name: "or of missing words", mode: ModeWebsearch, query: "home OR sea", text: lyrics},
+		{name: "or binds looser than and", mode: ModeWebsearch, query: "home far or sea", text: lyrics},
+		{name: "dangling or", mode: ModeWebsearch, query: "or ship or", text: lyrics, want: true},
+		{name: "negated or is a word", mode: ModeWebsearch, query: "ship -or", text: lyrics, want: true},
+		{name: "no stop words", mode: ModeWebsearch, query: "the", text: lyrics, want: true},
+		{name: "non-ASCII letters", mode: ModeWebsearch, query: "ЁЛКИ", text: "ёлки-палки", want: true},
+		{name: "phrase", mode: ModePhrase, query: "ship is taking", text: lyrics, want: true},
+		{name: "phrase out of order", mode: ModePhrase, query: "taking is ship", text: lyrics},
+		{name: "phrase ignores operators", mode: ModePhrase, query: "-far or away", text: "far or away", want: true},
+		{name: "plain", mode: ModePlain, query: "memories ship", text: lyrics, want: true},
+		{name: "plain ignores quotes", mode: ModePlain, query: `"me taking"`, text: lyrics, want: true},
+		{name: "plain ignores negation", mode: ModePlain, query: "-ship", text: lyrics, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.mode, tt.query).Match(tt.text); got != tt.want {
+				t.Fatalf("Parse(%s, %q).Match(%q): got %v, want %v", tt.mode, tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	for _, mode := range []Mode{ModeWebsearch, ModePhrase, ModePlain} {
+		for _, query := range []string{"", "   ", "!?", `""`, "-", `-""`, "or", "OR or"} {
+			if mode != ModeWebsearch && (query == "or" || query == "OR or") {
+				continue
+			}
+
+			q := Parse(mode, query)
+			if !q.Empty() || q.Match(lyrics) || q.Rank(lyrics) != 0 {
+				t.Fatalf("Parse(%s, %q): got a query that is not empty", mode, query)
+			}
+		}
+	}
+
+	if Parse(ModeWebsearch, "the").Empty() {
+		t.Fatalf("Parse of a stop word: got an empty query, want the word kept")
+	}
+}
+
+func TestRank(t *testing.T) {
+	q := Parse(ModeWebsearch, "far")
+
+	if got := q.Rank("no match here"); got != 0 {
+		t.Fatalf("Rank without a match: got %v, want 0", got)
+	}
+
+	short, long := q.Rank("far away"), q.Rank("far away from the memories of home")
+	if short <= long || long <= 0 {
+		t.Fatalf("Rank: got %v for the short text and %v for the long one, want the short one higher", short, long)
+	}
+
+	if once, thrice := q.Rank("far away, so so"), q.Rank("far far far away"); thrice <= once {
+		t.Fatalf("Rank: got %v for three hits and %v for one, want more hits higher", thrice, once)
+	}
+
+	if got := Parse(ModeWebsearch, "far -ship").Rank(lyrics); got != 0 {
+		t.Fatalf("Rank of an excluded text: got %v, want 0", got)
+	}
+}
+
+func TestHeadline(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		query string
+		text  string
+		want  string
+	}{
+		{name: "every hit", mode: ModeWebsearch, query: "far", text: lyrics, want: "<b>Far</b> away, this ship is taking me <b>far</b> away. <b>Far</b> away from the memories"},
+		{name: "phrase", mode: ModeWebsearch, query: `"ship is"`, text: lyrics, want: "Far away, this <b>ship</b> <b>is</b> taking me far away. Far away from the memories"},
+		{name: "negated words are not marked", mode: ModeWebsearch, query: "memories -ship", text: "this ship of memories", want: "this ship of <b>memories</b>"},
+		{name: "no hit", mode: ModeWebsearch, query: "home", text: "far away", want: "far away"},
+		{name: "empty text", mode: ModeWebsearch, query: "far", text: " ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.mode, tt.query).Headline(tt.text); got != tt.want {
+				t.Fatalf("Headline: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A long text is cut to 35 words, starting 5 words before the first hit.
+	var text, want []string
+	for i := 1; i <= 80; i++ {
+		text = append(text, fmt.Sprintf("w%d", i))
+	}
+
+	for i := 35; i <= 69; i++ {
+		want = append(want, fmt.Sprintf("w%d", i))
+	}
+
+	want[5] = "<b>w40</b>"
+
+	if got := Parse(ModePlain, "w40").Headline(strings.Join(text, " ")); got != strings.Join(want, " ") {
+		t.Fatalf("Headline of a long text: got %q, want %q", got, strings.Join(want, " "))
+	}
+}
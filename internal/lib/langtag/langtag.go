@@ -0,0 +1,23 @@
+// Package langtag checks BCP 47 language tags and brings them to one spelling, so en-us and
+// en-US name the same language.
+package langtag
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+var ErrInvalid = errors.New("invalid language tag")
+
+// Canonical returns the canonical spelling of a well-formed BCP 47 tag, such as en-US for en_us.
+// The undetermined language und is not accepted.
+func Canonical(value string) (string, error) {
+	tag, err := language.Parse(value)
+	if err != nil || tag == language.Und {
+		return "", fmt.Errorf("%w %q", ErrInvalid, value)
+	}
+
+	return tag.String(), nil
+}
@@ -0,0 +1,250 @@
+// Package lrc reads and writes time-synced lyrics in the LRC format and writes them as WebVTT
+// subtitles.
+package lrc
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCueDuration is how long the last line stays on screen in WebVTT, as nothing follows it.
+const DefaultCueDuration = 5 * time.Second
+
+var (
+	ErrMalformed = errors.New("malformed LRC")
+	ErrNoLines   = errors.New("LRC has no timed lines")
+)
+
+var (
+	timestampPattern = regexp.MustCompile(`^(\d{1,5}):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	tagPattern       = regexp.MustCompile(`^([A-Za-z#]+):(.*)$`)
+	wordTimePattern  = regexp.MustCompile(`<\d{1,5}:\d{1,2}(?:[.:]\d{1,3})?>`)
+)
+
+// SyntaxError reports the line of LRC lyrics that could not be read. It matches ErrMalformed.
+type SyntaxError struct {
+	Line   int
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: line %d: %s", ErrMalformed, e.Line, e.Reason)
+}
+
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrMalformed
+}
+
+// Line is one line of synced lyrics, shown from Time after the song starts. A line with empty
+// text marks an instrumental break.
+type Line struct {
+	Time time.Duration
+	Text string
+}
+
+// Parse reads LRC lyrics. A line may carry several timestamps, like [00:12.00][01:03.50]text, and
+// is repeated at each of them; ID tags such as [ti:...] are skipped, except [offset:...] which
+// shifts every line. Word timestamps of enhanced LRC are dropped. Lines are returned in time order.
+func Parse(text string) ([]Line, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []Line
+	var offset time.Duration
+
+	for n, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || strings.HasPrefix(raw, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(raw, "[") {
+			return nil, &SyntaxError{Line: n + 1, Reason: "no timestamp"}
+		}
+
+		var times []time.Duration
+		rest := raw
+
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, &SyntaxError{Line: n + 1, Reason: "unclosed bracket"}
+			}
+
+			group := rest[1:end]
+			rest = rest[end+1:]
+
+			at, err := ParseTimestamp(group)
+			if err == nil {
+				times = append(times, at)
+				continue
+			}
+
+			tag := tagPattern.FindStringSubmatch(group)
+			if tag == nil || len(times) > 0 {
+				return nil, &SyntaxError{Line: n + 1, Reason: fmt.Sprintf("invalid timestamp [%s]", group)}
+			}
+
+			if strings.TrimSpace(rest) != "" {
+				return nil, &SyntaxError{Line: n + 1, Reason: fmt.Sprintf("text after the [%s] tag", tag[1])}
+			}
+
+			if strings.EqualFold(tag[1], "offset") {
+				ms, err := strconv.ParseInt(strings.TrimSpace(tag[2]), 10, 32)
+				if err != nil {
+					return nil, &SyntaxError{Line: n + 1, Reason: fmt.Sprintf("invalid offset %q", tag[2])}
+				}
+
+				offset = time.Duration(ms) * time.Millisecond
+			}
+		}
+
+		words := strings.TrimSpace(wordTimePattern.ReplaceAllString(rest, ""))
+		for _, at := range times {
+			lines = append(lines, Line{Time: at, Text: strings.Join(strings.Fields(words), " ")})
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrNoLines
+	}
+
+	// A positive offset makes the lyrics appear sooner.
+	for i := range lines {
+		lines[i].Time = max(lines[i].Time-offset, 0)
+	}
+
+	slices.SortStableFunc(lines, func(a, b Line) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+
+	return lines, nil
+}
+
+// ParseTimestamp reads an LRC timestamp such as 01:23.45, 01:23.456, 01:23:45 or 01:23.
+func ParseTimestamp(value string) (time.Duration, error) {
+	parts := timestampPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if parts == nil {
+		return 0, fmt.Errorf("%w: invalid timestamp %q", ErrMalformed, value)
+	}
+
+	minutes, _ := strconv.Atoi(parts[1])
+	seconds, _ := strconv.Atoi(parts[2])
+	if seconds > 59 {
+		return 0, fmt.Errorf("%w: invalid timestamp %q", ErrMalformed, value)
+	}
+
+	at := time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+
+	// The fraction is in hundredths with two digits and in thousandths with three.
+	if fraction := parts[3]; fraction != "" {
+		value, _ := strconv.Atoi(fraction)
+		for i := len(fraction); i < 3; i++ {
+			value *= 10
+		}
+
+		at += time.Duration(value) * time.Millisecond
+	}
+
+	return at, nil
+}
+
+// Format writes lines as LRC, with the artist and title tags first when they are not empty.
+func Format(artist, title string, lines []Line) string {
+	var b strings.Builder
+
+	if artist = tagValue(artist); artist != "" {
+		fmt.Fprintf(&b, "[ar:%s]\n", artist)
+	}
+
+	if title = tagValue(title); title != "" {
+		fmt.Fprintf(&b, "[ti:%s]\n", title)
+	}
+
+	for _, line := range lines {
+		fmt.Fprintf(&b, "[%s]%s\n", timestamp(line.Time), line.Text)
+	}
+
+	return b.String()
+}
+
+// VTT writes lines as WebVTT cues. A cue lasts until the next later line starts, the last one for
+// DefaultCueDuration; instrumental breaks end the cue before them and get none of their own.
+func VTT(lines []Line) string {
+	var b strings.Builder
+
+	b.WriteString("WEBVTT\n")
+
+	cue := 0
+	for i, line := range lines {
+		if line.Text == "" {
+			continue
+		}
+
+		end := line.Time + DefaultCueDuration
+		for _, next := range lines[i+1:] {
+			if next.Time > line.Time {
+				end = next.Time
+				break
+			}
+		}
+
+		cue++
+		fmt.Fprintf(&b, "\n%d\n%s --> %s\n%s\n", cue, vttTimestamp(line.Time), vttTimestamp(end), escapeVTT(line.Text))
+	}
+
+	return b.String()
+}
+
+// At returns the index of the line being sung at t, the last one starting no later than t, or -1
+// before the first line.
+func At(lines []Line, t time.Duration) int {
+	i, _ := slices.BinarySearchFunc(lines, t, func(line Line, t time.Duration) int {
+		if line.Time <= t {
+			return -1
+		}
+
+		return 1
+	})
+
+	return i - 1
+}
+
+var tagEscaper = strings.NewReplacer("[", "(", "]", ")")
+
+// tagValue fits a value into an ID tag. LRC has no escapes, so a bracket would end the tag and a
+// line break would start a new line: brackets become parentheses and whitespace runs one space.
+func tagValue(value string) string {
+	return tagEscaper.Replace(strings.Join(strings.Fields(value), " "))
+}
+
+// timestamp writes hundredths, the usual LRC precision, unless the time needs thousandths.
+func timestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	if ms%10 != 0 {
+		return fmt.Sprintf("%02d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+	}
+
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
+
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeVTT writes cue text the way WebVTT requires: & and < escaped, > too so that no "-->" is
+// left to be read as a timing line, and on one line so that no blank line ends the cue early.
+func escapeVTT(text string) string {
+	return vttEscaper.Replace(strings.Join(strings.Fields(text), " "))
+}
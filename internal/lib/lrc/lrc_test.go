@@ -0,0 +1,172 @@
+package lrc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Line
+	}{
+		{
+			name: "plain",
+			text: "[00:01.00]far away\n[00:02.50]this ship",
+			want: []Line{{time.Second, "far away"}, {2500 * time.Millisecond, "this ship"}},
+		},
+		{
+			name: "several timestamps",
+			text: "[00:12.00][01:03.50]chorus\n[00:30.00]verse",
+			want: []Line{{12 * time.Second, "chorus"}, {30 * time.Second, "verse"}, {63500 * time.Millisecond, "chorus"}},
+		},
+		{
+			name: "tags, comments and blank lines",
+			text: "\ufeff[ar:Muse]\r\n[ti:Starlight]\r\n# comment\r\n\r\n[length: 03:59]\r\n[00:01.00]far away",
+			want: []Line{{time.Second, "far away"}},
+		},
+		{
+			name: "positive offset",
+			text: "[offset:+500]\n[00:01.00]far away\n[00:00.20]intro",
+			want: []Line{{0, "intro"}, {500 * time.Millisecond, "far away"}},
+		},
+		{
+			name: "negative offset",
+			text: "[offset:-250]\n[00:01.00]far away",
+			want: []Line{{1250 * time.Millisecond, "far away"}},
+		},
+		{
+			name: "offset after the lines",
+			text: "[00:01.00]far away\n[OFFSET:1000]",
+			want: []Line{{0, "far away"}},
+		},
+		{
+			name: "word timestamps and spacing",
+			text: "[00:01.00] <00:01.00>far   <00:01.50>away ",
+			want: []Line{{time.Second, "far away"}},
+		},
+		{
+			name: "instrumental break",
+			text: "[00:01.00]far away\n[00:04.00]",
+			want: []Line{{time.Second, "far away"}, {4 * time.Second, ""}},
+		},
+		{
+			name: "timestamp precision",
+			text: "[1:02]a\n[01:02.5]b\n[01:02.05]c\n[01:02.005]d\n[01:02:30]e",
+			want: []Line{
+				{62 * time.Second, "a"},
+				{62005 * time.Millisecond, "d"},
+				{62050 * time.Millisecond, "c"},
+				{62300 * time.Millisecond, "e"},
+				{62500 * time.Millisecond, "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("Parse: got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantLine int
+		wantErr  error
+	}{
+		{name: "no timestamp", text: "[00:01.00]far away\nthis ship", wantLine: 2, wantErr: ErrMalformed},
+		{name: "unclosed bracket", text: "[00:01.00", wantLine: 1, wantErr: ErrMalformed},
+		{name: "invalid timestamp", text: "[00:61.00]far away", wantLine: 1, wantErr: ErrMalformed},
+		{name: "tag after a timestamp", text: "[00:01.00][ar:Muse]far away", wantLine: 1, wantErr: ErrMalformed},
+		{name: "text after a tag", text: "[ar:Muse]far away", wantLine: 1, wantErr: ErrMalformed},
+		{name: "not a tag", text: "[00:01.00]a\n[far away]", wantLine: 2, wantErr: ErrMalformed},
+		{name: "invalid offset", text: "[offset:soon]\n[00:01.00]far away", wantLine: 1, wantErr: ErrMalformed},
+		{name: "tags only", text: "[ar:Muse]\n[ti:Starlight]", wantErr: ErrNoLines},
+		{name: "empty", text: "", wantErr: ErrNoLines},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.text)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse: got %v, want %v", err, tt.wantErr)
+			}
+
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) != (tt.wantLine != 0) || tt.wantLine != 0 && syntaxErr.Line != tt.wantLine {
+				t.Fatalf("Parse: got %v, want a syntax error at line %d", err, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	lines := []Line{{time.Second, "far away"}, {62005 * time.Millisecond, ""}, {time.Hour, "end"}}
+
+	got := Format("Muse [UK]", "Star]\nlight", lines)
+	want := "[ar:Muse (UK)]\n[ti:Star) light]\n[00:01.00]far away\n[01:02.005]\n[60:00.00]end\n"
+	if got != want {
+		t.Fatalf("Format: got %q, want %q", got, want)
+	}
+
+	parsed, err := Parse(got)
+	if err != nil || !slices.Equal(parsed, lines) {
+		t.Fatalf("Parse of Format: got %+v, %v, want %+v", parsed, err, lines)
+	}
+
+	if got := Format("", " \n", lines[:1]); got != "[00:01.00]far away\n" {
+		t.Fatalf("Format without tags: got %q", got)
+	}
+}
+
+func TestVTT(t *testing.T) {
+	lines := []Line{
+		{time.Second, "far <away>"},
+		{time.Second, "& away"},
+		{3 * time.Second, ""},
+		{5 * time.Second, "this ship"},
+		{3723 * time.Second, "end -->"},
+		{3724 * time.Second, "two\n\nlines\r\nhere"},
+	}
+
+	want := "WEBVTT\n" +
+		"\n1\n00:00:01.000 --> 00:00:03.000\nfar &lt;away&gt;\n" +
+		"\n2\n00:00:01.000 --> 00:00:03.000\n&amp; away\n" +
+		"\n3\n00:00:05.000 --> 01:02:03.000\nthis ship\n" +
+		"\n4\n01:02:03.000 --> 01:02:04.000\nend --&gt;\n" +
+		"\n5\n01:02:04.000 --> 01:02:09.000\ntwo lines here\n"
+
+	if got := VTT(lines); got != want {
+		t.Fatalf("VTT: got %q, want %q", got, want)
+	}
+}
+
+func TestAt(t *testing.T) {
+	lines := []Line{{time.Second, "a"}, {time.Second, "b"}, {3 * time.Second, "c"}}
+
+	for at, want := range map[time.Duration]int{
+		0:                      -1,
+		999 * time.Millisecond: -1,
+		time.Second:            1,
+		2 * time.Second:        1,
+		3 * time.Second:        2,
+		time.Hour:              2,
+	} {
+		if got := At(lines, at); got != want {
+			t.Fatalf("At(%v): got %d, want %d", at, got, want)
+		}
+	}
+}
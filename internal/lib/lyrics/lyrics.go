@@ -0,0 +1,271 @@
+package lyrics
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of verses. A verse without a marker is a plain verse unless it repeats, which makes it a chorus.
+const (
+	KindVerse     = "verse"
+	KindChorus    = "chorus"
+	KindPreChorus = "pre-chorus"
+	KindBridge    = "bridge"
+	KindRefrain   = "refrain"
+	KindIntro     = "intro"
+	KindOutro     = "outro"
+)
+
+// markers maps the label of a marker line such as [Chorus] or [Verse 2], lowercased and without
+// spaces, dashes or a trailing number, to the kind of verse it starts.
+var markers = map[string]string{
+	"verse":     KindVerse,
+	"chorus":    KindChorus,
+	"hook":      KindChorus,
+	"prechorus": KindPreChorus,
+	"bridge":    KindBridge,
+	"refrain":   KindRefrain,
+	"intro":     KindIntro,
+	"outro":     KindOutro,
+}
+
+// Verse is one stanza of parsed lyrics.
+type Verse struct {
+	Kind string
+	Text string
+}
+
+// Normalize brings lyrics to the form they are stored in: LF line endings, no trailing spaces,
+// no blank lines around the text and exactly one blank line between verses.
+func Normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []string
+	blank := false
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Parse normalizes lyrics and splits them into verses at blank lines and marker lines. A marker
+// such as [Chorus] or [Verse 2: Artist] sets the kind of the verse it starts and is left out of its
+// text; a marker with no lines after it repeats the previous verse of its kind, and is dropped when
+// there is none. Verses without a marker that occur more than once are choruses.
+func Parse(text string) []Verse {
+	text = Normalize(text)
+	if text == "" {
+		return nil
+	}
+
+	var verses []Verse
+	var marked []bool
+	var lines []string
+	kind, hasMarker := KindVerse, false
+
+	flush := func() {
+		if len(lines) == 0 && !hasMarker {
+			return
+		}
+
+		verses, marked = append(verses, Verse{Kind: kind, Text: strings.Join(lines, "\n")}), append(marked, hasMarker)
+		lines, kind, hasMarker = nil, KindVerse, false
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if marker, ok := markerKind(line); ok {
+			flush()
+			kind, hasMarker = marker, true
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	flush()
+
+	seen := make(map[string]int)
+	for _, verse := range verses {
+		seen[key(verse.Text)]++
+	}
+
+	for i := range verses {
+		if !marked[i] && verses[i].Text != "" && seen[key(verses[i].Text)] > 1 {
+			verses[i].Kind = KindChorus
+		}
+	}
+
+	parsed := verses[:0]
+	for _, verse := range verses {
+		if verse.Text == "" {
+			verse.Text = previous(parsed, verse.Kind)
+		}
+
+		// A lone marker with nothing to repeat would be served as an empty verse.
+		if verse.Text != "" {
+			parsed = append(parsed, verse)
+		}
+	}
+
+	return parsed
+}
+
+// Repeats returns, for every verse text, the number of the first earlier verse with the same
+// text, or zero when the text appears for the first time. Case and spacing are ignored.
+func Repeats(texts []string) []int {
+	first := make(map[string]int)
+	repeats := make([]int, len(texts))
+
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+
+		if n, ok := first[key(text)]; ok {
+			repeats[i] = n
+			continue
+		}
+
+		first[key(text)] = i + 1
+	}
+
+	return repeats
+}
+
+// markerKind reports whether the line is a section marker and the kind of verse it starts.
+func markerKind(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) < 3 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+
+	label, _, _ := strings.Cut(line[1:len(line)-1], ":")
+
+	words := strings.Fields(strings.ToLower(label))
+	if len(words) > 1 && strings.Trim(words[len(words)-1], "0123456789") == "" {
+		words = words[:len(words)-1]
+	}
+
+	kind, ok := markers[strings.ReplaceAll(strings.Join(words, ""), "-", "")]
+
+	return kind, ok
+}
+
+// previous returns the text of the last of verses of the kind, or an empty string when there is none.
+func previous(verses []Verse, kind string) string {
+	for i := len(verses) - 1; i >= 0; i-- {
+		if verses[i].Kind == kind {
+			return verses[i].Text
+		}
+	}
+
+	return ""
+}
+
+// key is what two verses have in common when they repeat each other.
+func key(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// SplitVerses returns the text of every verse, numbered the same way as the stored verses.
+func SplitVerses(lyrics string) []string {
+	var texts []string
+	for _, verse := range Parse(lyrics) {
+		texts = append(texts, verse.Text)
+	}
+
+	return texts
+}
+
+// Hit is a line of the lyrics containing a searched phrase. Verse and Line count from one, Line
+// within its verse.
+type Hit struct {
+	Verse int
+	Line  int
+	Text  string
+}
+
+// Find returns every line of lyrics containing phrase, ignoring case. The phrase has to fit on one
+// line, and verses are numbered as SplitVerses splits them.
+func Find(lyrics, phrase string) []Hit {
+	phrase = strings.ToLower(phrase)
+
+	var hits []Hit
+	for v, verse := range SplitVerses(lyrics) {
+		for l, line := range strings.Split(verse, "\n") {
+			if strings.Contains(strings.ToLower(line), phrase) {
+				hits = append(hits, Hit{Verse: v + 1, Line: l + 1, Text: line})
+			}
+		}
+	}
+
+	return hits
+}
+
+// ErrInvalidRange is returned for a verse range that is malformed or empty.
+var ErrInvalidRange = errors.New("invalid verse range")
+
+// Range is a span of verse numbers counting from one, both ends included. To is zero when the
+// range runs to the last verse.
+type Range struct {
+	From int
+	To   int
+}
+
+// ParseRange parses a verse range written as 2-4, 3, 2- or -4. An empty value covers every verse.
+func ParseRange(value string) (Range, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Range{From: 1}, nil
+	}
+
+	from, to, isRange := strings.Cut(value, "-")
+	if !isRange {
+		to = from
+	}
+
+	var r Range
+	var err error
+
+	r.From = 1
+	if from != "" {
+		if r.From, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+			return Range{}, fmt.Errorf("%w %q: %w", ErrInvalidRange, value, err)
+		}
+	}
+
+	if to != "" {
+		if r.To, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+			return Range{}, fmt.Errorf("%w %q: %w", ErrInvalidRange, value, err)
+		}
+	}
+
+	if r.From < 1 || to != "" && r.To < r.From {
+		return Range{}, fmt.Errorf("%w %q", ErrInvalidRange, value)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,149 @@
+package lyrics
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "clean", text: "far away\nthis ship\n\nstarlight", want: "far away\nthis ship\n\nstarlight"},
+		{name: "crlf", text: "far away\r\nthis ship\r\n\r\nstarlight", want: "far away\nthis ship\n\nstarlight"},
+		{name: "cr", text: "far away\rthis ship", want: "far away\nthis ship"},
+		{name: "triple newlines", text: "far away\n\n\n\nstarlight", want: "far away\n\nstarlight"},
+		{name: "blank lines around", text: "\n\n  \nfar away\n\n\t\n", want: "far away"},
+		{name: "trailing spaces", text: "far away  \n \t\nstarlight\t", want: "far away\n\nstarlight"},
+		{name: "leading spaces kept", text: "  far away", want: "  far away"},
+		{name: "empty", text: " \r\n\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); got != tt.want {
+				t.Fatalf("Normalize(%q): got %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Verse
+	}{
+		{
+			name: "plain verses",
+			text: "far away\nthis ship\r\n\r\n\r\nstarlight",
+			want: []Verse{{KindVerse, "far away\nthis ship"}, {KindVerse, "starlight"}},
+		},
+		{
+			name: "markers",
+			text: "[Intro]\nhum\n[Verse 2: Matt]\nnear\n[Pre-Chorus]\nhold you\n\n[Hook]\nooh\n[OUTRO]\nbye",
+			want: []Verse{{KindIntro, "hum"}, {KindVerse, "near"}, {KindPreChorus, "hold you"}, {KindChorus, "ooh"}, {KindOutro, "bye"}},
+		},
+		{
+			name: "repeated unmarked chorus",
+			text: "far away\n\nOoh\nstarlight\n\nnear\n\nooh\n  starlight",
+			want: []Verse{{KindVerse, "far away"}, {KindChorus, "Ooh\nstarlight"}, {KindVerse, "near"}, {KindChorus, "ooh\n  starlight"}},
+		},
+		{
+			name: "marked verse keeps its kind when repeated",
+			text: "[Bridge]\nooh\n\nooh",
+			want: []Verse{{KindBridge, "ooh"}, {KindChorus, "ooh"}},
+		},
+		{
+			name: "lone marker repeats the previous verse of its kind",
+			text: "[Chorus]\nooh\n\n[Verse]\nfar away\n\n[Chorus]",
+			want: []Verse{{KindChorus, "ooh"}, {KindVerse, "far away"}, {KindChorus, "ooh"}},
+		},
+		{
+			name: "lone marker with nothing to repeat",
+			text: "far away\n\n[Bridge]\n\n[Chorus]",
+			want: []Verse{{KindVerse, "far away"}},
+		},
+		{
+			name: "brackets that are not markers",
+			text: "[far away]\n[]\n[Verse two]",
+			want: []Verse{{KindVerse, "[far away]\n[]\n[Verse two]"}},
+		},
+		{
+			name: "empty",
+			text: "\r\n \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !slices.Equal(got, tt.want) {
+				t.Fatalf("Parse(%q): got %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepeats(t *testing.T) {
+	got := Repeats([]string{"far away", "Ooh", "near", "", "ooh ", "FAR\naway", ""})
+	if want := []int{0, 0, 0, 0, 2, 1, 0}; !slices.Equal(got, want) {
+		t.Fatalf("Repeats: got %v, want %v", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	text := "[Intro]\nfar away\n\nthis ship\nis taking me FAR AWAY\n\nfar\naway"
+
+	want := []Hit{{Verse: 1, Line: 1, Text: "far away"}, {Verse: 2, Line: 2, Text: "is taking me FAR AWAY"}}
+	if got := Find(text, "Far Away"); !slices.Equal(got, want) {
+		t.Fatalf("Find: got %+v, want %+v", got, want)
+	}
+
+	if got := Find(text, "intro"); got != nil {
+		t.Fatalf("Find in a marker: got %+v, want no hits", got)
+	}
+
+	if got := Find(text, "far\naway"); got != nil {
+		t.Fatalf("Find across lines: got %+v, want no hits", got)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Range
+		wantErr bool
+	}{
+		{value: "", want: Range{From: 1}},
+		{value: "3", want: Range{From: 3, To: 3}},
+		{value: "2-4", want: Range{From: 2, To: 4}},
+		{value: " 2 - 4 ", want: Range{From: 2, To: 4}},
+		{value: "2-", want: Range{From: 2}},
+		{value: "-4", want: Range{From: 1, To: 4}},
+		{value: "4-4", want: Range{From: 4, To: 4}},
+		{value: "0-", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "4-2", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "2-x", wantErr: true},
+		{value: "-1-4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRange(tt.value)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidRange) {
+				t.Fatalf("ParseRange(%q): got %+v, %v, want ErrInvalidRange", tt.value, got, err)
+			}
+
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Fatalf("ParseRange(%q): got %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package match
+
+import (
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// Resolve returns the mode without its case-insensitive prefix and whether the match ignores case.
+// An empty mode resolves to def.
+func Resolve(mode, def models.MatchMode) (models.MatchMode, bool) {
+	if mode == "" {
+		mode = def
+	}
+
+	switch mode {
+	case models.MatchIExact:
+		return models.MatchExact, true
+	case models.MatchIPrefix:
+		return models.MatchPrefix, true
+	case models.MatchIContains:
+		return models.MatchContains, true
+	default:
+		return mode, false
+	}
+}
+
+func String(mode, def models.MatchMode, value, pattern string) bool {
+	kind, insensitive := Resolve(mode, def)
+	if insensitive {
+		value, pattern = strings.ToLower(value), strings.ToLower(pattern)
+	}
+
+	switch kind {
+	case models.MatchPrefix:
+		return strings.HasPrefix(value, pattern)
+	case models.MatchContains:
+		return strings.Contains(value, pattern)
+	default:
+		return value == pattern
+	}
+}
+
+// EscapeLike escapes LIKE wildcards so that pattern matches literally with ESCAPE '\'.
+func EscapeLike(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+}
@@ -0,0 +1,139 @@
+package releasedate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+const (
+	Layout      = "02.01.2006"
+	monthLayout = "01.2006"
+	yearLayout  = "2006"
+)
+
+var (
+	ErrInvalidDate  = errors.New("invalid release date")
+	ErrInvalidRange = errors.New("release date range is empty")
+)
+
+// Period is the span of days covered by a full (DD.MM.YYYY), year-month (MM.YYYY) or year-only (YYYY) date.
+type Period struct {
+	First time.Time
+	Last  time.Time
+}
+
+// Range holds inclusive day bounds. A nil bound is open.
+type Range struct {
+	From *time.Time
+	To   *time.Time
+}
+
+func Parse(value string) (time.Time, error) {
+	const op = "lib.releasedate.Parse"
+
+	date, err := time.Parse(Layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w %q", op, ErrInvalidDate, value)
+	}
+
+	return date, nil
+}
+
+func Format(date time.Time) string {
+	return date.Format(Layout)
+}
+
+func ParsePeriod(value string) (Period, error) {
+	const op = "lib.releasedate.ParsePeriod"
+
+	if date, err := time.Parse(Layout, value); err == nil {
+		return Period{First: date, Last: date}, nil
+	}
+
+	if month, err := time.Parse(monthLayout, value); err == nil {
+		return Period{First: month, Last: month.AddDate(0, 1, -1)}, nil
+	}
+
+	if year, err := time.Parse(yearLayout, value); err == nil {
+		return Period{First: year, Last: year.AddDate(1, 0, -1)}, nil
+	}
+
+	return Period{}, fmt.Errorf("%s: %w %q", op, ErrInvalidDate, value)
+}
+
+// FilterRange folds release_from, release_to and the legacy release_date/bigger pair into one inclusive range.
+// An inclusive lower bound starts at the first day of its period and an exclusive one right after the last day;
+// an inclusive upper bound ends at the last day of its period and an exclusive one right before the first day.
+func FilterRange(filter models.SongFilter) (Range, error) {
+	const op = "lib.releasedate.FilterRange"
+
+	var r Range
+
+	if filter.ReleaseFrom != "" {
+		period, err := ParsePeriod(filter.ReleaseFrom)
+		if err != nil {
+			return Range{}, fmt.Errorf("%s: release_from: %w", op, err)
+		}
+
+		from := period.First
+		if filter.ReleaseFromExclusive {
+			from = period.Last.AddDate(0, 0, 1)
+		}
+		r.from(from)
+	}
+
+	if filter.ReleaseTo != "" {
+		period, err := ParsePeriod(filter.ReleaseTo)
+		if err != nil {
+			return Range{}, fmt.Errorf("%s: release_to: %w", op, err)
+		}
+
+		to := period.Last
+		if filter.ReleaseToExclusive {
+			to = period.First.AddDate(0, 0, -1)
+		}
+		r.to(to)
+	}
+
+	if filter.ReleaseDate != "" {
+		date, err := Parse(filter.ReleaseDate)
+		if err != nil {
+			return Range{}, fmt.Errorf("%s: release_date: %w", op, err)
+		}
+
+		if filter.Later {
+			r.from(date.AddDate(0, 0, 1))
+		} else {
+			r.to(date)
+		}
+	}
+
+	if r.From != nil && r.To != nil && r.From.After(*r.To) {
+		return Range{}, fmt.Errorf("%s: %w", op, ErrInvalidRange)
+	}
+
+	return r, nil
+}
+
+func (r Range) Empty() bool {
+	return r.From == nil && r.To == nil
+}
+
+func (r Range) Contains(date time.Time) bool {
+	return (r.From == nil || !date.Before(*r.From)) && (r.To == nil || !date.After(*r.To))
+}
+
+func (r *Range) from(date time.Time) {
+	if r.From == nil || date.After(*r.From) {
+		r.From = &date
+	}
+}
+
+func (r *Range) to(date time.Time) {
+	if r.To == nil || date.Before(*r.To) {
+		r.To = &date
+	}
+}
@@ -0,0 +1,108 @@
+package sorting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Field string
+
+const (
+	FieldBand    Field = "band_name"
+	FieldTitle   Field = "song_title"
+	FieldRelease Field = "release_date"
+	FieldUpdated Field = "updated"
+	FieldRank    Field = "rank"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+var fields = map[Field]bool{
+	FieldBand:    true,
+	FieldTitle:   true,
+	FieldRelease: true,
+	FieldUpdated: true,
+	FieldRank:    true,
+}
+
+type Key struct {
+	Field Field
+	Desc  bool
+}
+
+// Parse reads a comma separated list of sort fields, where a leading "-" means descending,
+// e.g. "band_name,-release_date". Only whitelisted fields are accepted.
+func Parse(spec string) ([]Key, error) {
+	const op = "lib.sorting.Parse"
+
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var keys []Key
+	seen := map[Field]bool{}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+
+		key := Key{Field: Field(part)}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			key = Key{Field: Field(name), Desc: true}
+		}
+
+		if !fields[key.Field] {
+			return nil, fmt.Errorf("%s: %w: unknown field %q", op, ErrInvalidSort, part)
+		}
+
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%s: %w: duplicate field %q", op, ErrInvalidSort, key.Field)
+		}
+		seen[key.Field] = true
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ForSearch parses spec like Parse for a listing that may be ranked by a search. Sorting by rank
+// needs a search, and a search without an explicit sort is ordered by descending rank.
+func ForSearch(spec string, search bool) ([]Key, error) {
+	const op = "lib.sorting.ForSearch"
+
+	keys, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if !search {
+		for _, key := range keys {
+			if key.Field == FieldRank {
+				return nil, fmt.Errorf("%s: %w: sorting by rank needs a search", op, ErrInvalidSort)
+			}
+		}
+
+		return keys, nil
+	}
+
+	if len(keys) == 0 {
+		return []Key{{Field: FieldRank, Desc: true}}, nil
+	}
+
+	return keys, nil
+}
+
+func Format(keys []Key) string {
+	parts := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if key.Desc {
+			parts = append(parts, "-"+string(key.Field))
+		} else {
+			parts = append(parts, string(key.Field))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,92 @@
+package sorting
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Key
+		wantErr bool
+	}{
+		{spec: ""},
+		{spec: "  "},
+		{spec: "band_name", want: []Key{{Field: FieldBand}}},
+		{spec: "-release_date,song_title", want: []Key{{Field: FieldRelease, Desc: true}, {Field: FieldTitle}}},
+		{spec: " band_name , -updated ", want: []Key{{Field: FieldBand}, {Field: FieldUpdated, Desc: true}}},
+		{spec: "-rank", want: []Key{{Field: FieldRank, Desc: true}}},
+		{spec: "colour", wantErr: true},
+		{spec: "Band_Name", wantErr: true},
+		{spec: "id", wantErr: true},
+		{spec: "band_name;drop table songs", wantErr: true},
+		{spec: "--band_name", wantErr: true},
+		{spec: "+band_name", wantErr: true},
+		{spec: "band_name,", wantErr: true},
+		{spec: ",", wantErr: true},
+		{spec: "band_name,-band_name", wantErr: true},
+		{spec: "updated,updated", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.spec)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSort) {
+				t.Fatalf("Parse(%q): got %v, %v, want ErrInvalidSort", tt.spec, got, err)
+			}
+
+			continue
+		}
+
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Fatalf("Parse(%q): got %v, %v, want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestForSearch(t *testing.T) {
+	tests := []struct {
+		spec    string
+		search  bool
+		want    []Key
+		wantErr bool
+	}{
+		{spec: "", search: false},
+		{spec: "", search: true, want: []Key{{Field: FieldRank, Desc: true}}},
+		{spec: "band_name", search: true, want: []Key{{Field: FieldBand}}},
+		{spec: "rank,-updated", search: true, want: []Key{{Field: FieldRank}, {Field: FieldUpdated, Desc: true}}},
+		{spec: "-rank", search: false, wantErr: true},
+		{spec: "band_name,rank", search: false, wantErr: true},
+		{spec: "colour", search: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ForSearch(tt.spec, tt.search)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSort) {
+				t.Fatalf("ForSearch(%q, %v): got %v, %v, want ErrInvalidSort", tt.spec, tt.search, got, err)
+			}
+
+			continue
+		}
+
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Fatalf("ForSearch(%q, %v): got %v, %v, want %v", tt.spec, tt.search, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, spec := range []string{"", "band_name", "-release_date,song_title,-rank"} {
+		keys, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+
+		if got := Format(keys); got != spec {
+			t.Fatalf("Format(Parse(%q)): got %q", spec, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package trigram computes trigram similarity the way pg_trgm does, for the backends without it.
+package trigram
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity returns the share of trigrams a and b have in common, from 0 to 1, like pg_trgm's similarity().
+func Similarity(a, b string) float64 {
+	left, right := trigrams(a), trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range left {
+		if right[trigram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(left)+len(right)-shared)
+}
+
+// trigrams splits text into lowercased words of letters and digits and collects the trigrams of
+// every word padded with two spaces in front and one behind.
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+
+	return set
+}
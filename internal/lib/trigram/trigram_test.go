@@ -0,0 +1,39 @@
+package trigram
+
+import (
+	"math"
+	"testing"
+)
+
+// The expected values are what pg_trgm's similarity() returns for the same arguments.
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"word", "two words", 4.0 / 11},
+		{"Muse", "Muce", 2.0 / 8},
+		{"Muse", "Museum", 4.0 / 8},
+		{"a", "ab", 1.0 / 4},
+		{"MUSE", "muse", 1},
+		{"Muse muse", "muse", 1},
+		{"black hole", "hole black", 1},
+		{"rock-n-roll", "rock n roll", 1},
+		{"Кино", "кино", 1},
+		{"ёлки", "елки", 2.0 / 8},
+		{"Muse", "Placebo", 0},
+		{"", "muse", 0},
+		{"!!!", "!!!", 0},
+	}
+
+	for _, tt := range tests {
+		got := Similarity(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("Similarity(%q, %q): got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+
+		if reverse := Similarity(tt.b, tt.a); reverse != got {
+			t.Fatalf("Similarity(%q, %q): got %v, want %v as the other way round", tt.b, tt.a, reverse, got)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/clients/musicinfo"
+	"github.com/stepan41k/Testovoe/internal/config"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+)
+
+type Jobs interface {
+	ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (job models.EnrichmentJob, err error)
+	CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) (err error)
+	RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) (err error)
+	FailEnrichmentJob(ctx context.Context, jobID int64, reason string) (err error)
+	RequeueFailedJobs(ctx context.Context) (count int64, err error)
+}
+
+type Info interface {
+	SongInfo(ctx context.Context, group, song string) (musicinfo.SongDetail, error)
+}
+
+type Worker struct {
+	jobs Jobs
+	info Info
+	cfg  config.Enrichment
+	log  *slog.Logger
+}
+
+func New(jobs Jobs, info Info, cfg config.Enrichment, log *slog.Logger) *Worker {
+	return &Worker{
+		jobs: jobs,
+		info: info,
+		cfg:  cfg,
+		log:  log,
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) {
+	const op = "service.enrichment.Run"
+
+	log := w.log.With(
+		slog.String("op", op),
+		slog.Int("workers", w.cfg.Workers),
+	)
+
+	log.Info("starting enrichment workers")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	log.Info("enrichment workers stopped")
+}
+
+func (w *Worker) RequeueFailed(ctx context.Context) (int64, error) {
+	const op = "service.enrichment.RequeueFailed"
+
+	log := w.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("requeueing failed jobs")
+
+	count, err := w.jobs.RequeueFailedJobs(ctx)
+	if err != nil {
+		log.Error("failed to requeue jobs", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("jobs requeued", slog.Int64("count", count))
+
+	return count, nil
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		processed := w.process(ctx)
+		if processed {
+			if ctx.Err() != nil {
+				return
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context) bool {
+	const op = "service.enrichment.process"
+
+	log := w.log.With(
+		slog.String("op", op),
+	)
+
+	job, err := w.jobs.ClaimEnrichmentJob(ctx, w.cfg.Lease)
+	if err != nil {
+		if !errors.Is(err, errs.ErrNoJobs) && ctx.Err() == nil {
+			log.Error("failed to claim job", sl.Err(err))
+		}
+
+		return false
+	}
+
+	log = log.With(
+		slog.Int64("job_id", job.ID),
+		slog.String("group", job.BandName),
+		slog.String("song", job.SongTitle),
+		slog.Int("attempt", job.Attempts),
+	)
+
+	// The job stays leased, so a job interrupted by shutdown is picked up again once the lease expires.
+	detail, err := w.info.SongInfo(ctx, job.BandName, job.SongTitle)
+	if err != nil {
+		if ctx.Err() != nil {
+			return true
+		}
+
+		w.handleFailure(context.WithoutCancel(ctx), log, job, err)
+
+		return true
+	}
+
+	err = w.jobs.CompleteEnrichmentJob(context.WithoutCancel(ctx), job.ID, models.Song{
+		ReleaseDate: detail.ReleaseDate,
+		Lyrics:      detail.Text,
+		Link:        detail.Link,
+	})
+	if err != nil {
+		log.Error("failed to complete job", sl.Err(err))
+
+		return true
+	}
+
+	log.Info("song enriched")
+
+	return true
+}
+
+func (w *Worker) handleFailure(ctx context.Context, log *slog.Logger, job models.EnrichmentJob, cause error) {
+	if !retryable(cause) || job.Attempts >= w.cfg.MaxAttempts {
+		log.Warn("enrichment failed, moving job to dead letter", sl.Err(cause))
+
+		if err := w.jobs.FailEnrichmentJob(ctx, job.ID, cause.Error()); err != nil {
+			log.Error("failed to mark job as dead", sl.Err(err))
+		}
+
+		return
+	}
+
+	delay := w.backoff(job.Attempts)
+
+	log.Warn("enrichment failed, retrying", sl.Err(cause), slog.Duration("delay", delay))
+
+	if err := w.jobs.RetryEnrichmentJob(ctx, job.ID, delay, cause.Error()); err != nil {
+		log.Error("failed to reschedule job", sl.Err(err))
+	}
+}
+
+func (w *Worker) backoff(attempt int) time.Duration {
+	delay := w.cfg.BaseBackoff
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+
+	return min(delay, w.cfg.MaxBackoff)
+}
+
+// retryable reports whether another attempt may succeed. An unknown song, a rejected request and
+// an answer that cannot be used, such as one without a release date, stay the same on a retry.
+func retryable(err error) bool {
+	return !errors.Is(err, musicinfo.ErrSongNotFound) && !errors.Is(err, musicinfo.ErrBadRequest) &&
+		!errors.Is(err, musicinfo.ErrInvalidResponse)
+}
@@ -0,0 +1,245 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/clients/musicinfo"
+	"github.com/stepan41k/Testovoe/internal/config"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/infomock"
+	"github.com/stepan41k/Testovoe/internal/storage/memory"
+)
+
+var testConfig = config.Enrichment{
+	Workers:      1,
+	PollInterval: 10 * time.Millisecond,
+	Lease:        time.Minute,
+	MaxAttempts:  3,
+	BaseBackoff:  2 * time.Second,
+	MaxBackoff:   time.Minute,
+}
+
+var fixture = infomock.Fixture{
+	Seed: 1,
+	Songs: []infomock.Song{
+		{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006", Text: "Far away\n\nStarlight", Link: "https://example.com/starlight"},
+		{Group: "Muse", Song: "Hysteria", Status: http.StatusInternalServerError},
+		{Group: "Muse", Song: "Uprising", Status: http.StatusBadRequest},
+		{Group: "Muse", Song: "Undated", Text: "No date"},
+		{Group: "Muse", Song: "Slow", ReleaseDate: "03.09.2006", Latency: time.Second},
+	},
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name     string
+		song     string
+		attempts int
+		want     outcome
+	}{
+		{name: "found", song: "Starlight", attempts: 1, want: outcome{completed: &models.Song{
+			ReleaseDate: "03.09.2006", Lyrics: "Far away\n\nStarlight", Link: "https://example.com/starlight",
+		}}},
+		{name: "upstream error", song: "Hysteria", attempts: 1, want: outcome{retry: 2 * time.Second}},
+		{name: "upstream error again", song: "Hysteria", attempts: 2, want: outcome{retry: 4 * time.Second}},
+		{name: "upstream error on the last attempt", song: "Hysteria", attempts: 3, want: outcome{dead: true}},
+		{name: "timeout", song: "Slow", attempts: 1, want: outcome{retry: 2 * time.Second}},
+		{name: "unknown song", song: "Unknown", attempts: 1, want: outcome{dead: true}},
+		{name: "rejected request", song: "Uprising", attempts: 1, want: outcome{dead: true}},
+		{name: "no release date", song: "Undated", attempts: 1, want: outcome{dead: true}},
+	}
+
+	info := infoClient(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := &fakeJobs{job: models.EnrichmentJob{ID: 7, SongID: 1, BandName: "Muse", SongTitle: tt.song, Attempts: tt.attempts}}
+			worker := New(jobs, info, testConfig, slog.New(slog.DiscardHandler))
+
+			if !worker.process(context.Background()) {
+				t.Fatalf("process: got no job processed")
+			}
+
+			if got := jobs.outcome(); !got.equal(tt.want) {
+				t.Fatalf("process: got %+v, want %+v", got, tt.want)
+			}
+
+			if worker.process(context.Background()) {
+				t.Fatalf("process without jobs: got a job processed")
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	worker := New(nil, nil, testConfig, slog.New(slog.DiscardHandler))
+
+	for attempt, want := range map[int]time.Duration{
+		1:   2 * time.Second,
+		2:   4 * time.Second,
+		3:   8 * time.Second,
+		5:   32 * time.Second,
+		6:   time.Minute,
+		100: time.Minute,
+	} {
+		if got := worker.backoff(attempt); got != want {
+			t.Fatalf("backoff(%d): got %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	starlight, err := store.AddNewSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	unknown, err := store.AddNewSong(ctx, models.Song{BandName: "Muse", SongTitle: "Unknown"})
+	if err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	worker := New(store, infoClient(t), testConfig, slog.New(slog.DiscardHandler))
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		worker.Run(runCtx)
+		close(done)
+	}()
+
+	want := map[int64]string{starlight: models.SongStatusEnriched, unknown: models.SongStatusFailed}
+	waitFor(t, func() bool {
+		for id, status := range want {
+			if song, _ := store.GetSong(ctx, id); song.Status != status {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	stop()
+	<-done
+
+	if song, _ := store.GetSong(ctx, starlight); song.Lyrics != "Far away\n\nStarlight" || song.ReleaseDate != "03.09.2006" {
+		t.Fatalf("enriched song: got %+v", song)
+	}
+
+	count, err := worker.RequeueFailed(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("RequeueFailed: got %d, %v, want 1", count, err)
+	}
+
+	if song, _ := store.GetSong(ctx, unknown); song.Status != models.SongStatusPending {
+		t.Fatalf("requeued song: got status %q, want pending", song.Status)
+	}
+}
+
+// outcome is what a processed job ended with: completion, a retry after a delay or the dead letter.
+type outcome struct {
+	completed *models.Song
+	retry     time.Duration
+	dead      bool
+}
+
+func (o outcome) equal(other outcome) bool {
+	if (o.completed == nil) != (other.completed == nil) || o.completed != nil && *o.completed != *other.completed {
+		return false
+	}
+
+	return o.retry == other.retry && o.dead == other.dead
+}
+
+// fakeJobs hands out a single job and records what the worker did with it.
+type fakeJobs struct {
+	mu      sync.Mutex
+	job     models.EnrichmentJob
+	claimed bool
+	result  outcome
+}
+
+func (f *fakeJobs) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.claimed {
+		return models.EnrichmentJob{}, errs.ErrNoJobs
+	}
+
+	f.claimed = true
+
+	return f.job, nil
+}
+
+func (f *fakeJobs) CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.result.completed = &details
+
+	return nil
+}
+
+func (f *fakeJobs) RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.result.retry = delay
+
+	return nil
+}
+
+func (f *fakeJobs) FailEnrichmentJob(ctx context.Context, jobID int64, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.result.dead = true
+
+	return nil
+}
+
+func (f *fakeJobs) RequeueFailedJobs(ctx context.Context) (int64, error) {
+	return 0, errors.New("not supported")
+}
+
+func (f *fakeJobs) outcome() outcome {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.result
+}
+
+func infoClient(t *testing.T) *musicinfo.Client {
+	t.Helper()
+
+	server := httptest.NewServer(infomock.New(fixture, slog.New(slog.DiscardHandler)))
+	t.Cleanup(server.Close)
+
+	return musicinfo.New(slog.New(slog.DiscardHandler), server.URL, 100*time.Millisecond)
+}
+
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -5,34 +5,75 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
 	"github.com/stepan41k/Testovoe/internal/domain/models"
 	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
-	"github.com/stepan41k/Testovoe/internal/storage"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	DefaultSearchLanguage = "simple"
 )
 
 type Music interface {
-	GetSongs(ctx context.Context, song models.SongFilter) (songs []models.Song, err error)
-	GetTextSong(ctx context.Context, song models.SongLyrics) (verse string, err error)
+	GetSongs(ctx context.Context, song models.SongFilter) (songs models.SongList, err error)
+	GetTextSong(ctx context.Context, song models.SongLyrics) (verse models.Verse, err error)
 	DeleteSong(ctx context.Context, song models.Song) (id int64, err error)
 	UpdateSong(ctx context.Context, songDetails models.Song) (id int64, err error)
 	AddNewSong(ctx context.Context, song models.Song) (id int64, err error)
+	GetSong(ctx context.Context, id int64) (song models.Song, err error)
+	ReplaceSong(ctx context.Context, id int64, song models.Song) (replaced models.Song, err error)
+	PatchSong(ctx context.Context, id int64, patch models.SongPatch) (patched models.Song, err error)
+	DeleteSongByID(ctx context.Context, id int64) error
+	GetVerse(ctx context.Context, id int64, n int) (verse models.Verse, err error)
+	GetVerses(ctx context.Context, id int64) (verses []models.Verse, err error)
+	GetTextVerses(ctx context.Context, song models.SongLyrics) (verses []models.Verse, err error)
+	ReplaceSyncedLyrics(ctx context.Context, id int64, lines []models.SyncedLine) error
+	GetSyncedLyrics(ctx context.Context, id int64) (lines []models.SyncedLine, err error)
+	DeleteSyncedLyrics(ctx context.Context, id int64) error
+	SaveTranslation(ctx context.Context, id int64, language, text string) (created bool, err error)
+	GetTranslation(ctx context.Context, id int64, language string) (translation models.Translation, err error)
+	GetTextTranslation(ctx context.Context, song models.SongLyrics) (translation models.Translation, err error)
+	GetTranslations(ctx context.Context, id int64) (translations []models.Translation, err error)
+	DeleteTranslation(ctx context.Context, id int64, language string) error
+	ClosestNames(ctx context.Context, band, title string, threshold float64) (names models.DidYouMean, err error)
+}
+
+// NameCache holds band and song names that go stale when songs are added, changed or deleted.
+type NameCache interface {
+	Invalidate()
 }
 
 type MusicService struct {
 	music Music
+	names NameCache
 	log *slog.Logger
 }
 
-func New(music Music, log *slog.Logger) *MusicService {
+// New creates the service. names may be nil when no names are cached.
+func New(music Music, names NameCache, log *slog.Logger) *MusicService {
 	return &MusicService{
 		music: music,
+		names: names,
 		log: log,
 	}
 }
 
+// namesChanged tells the name cache that a write went through.
+func (m *MusicService) namesChanged() {
+	if m.names != nil {
+		m.names.Invalidate()
+	}
+}
+
 
-func (m *MusicService) GetSongs(ctx context.Context, song models.SongFilter) ([]models.Song, error) {
+func (m *MusicService) GetSongs(ctx context.Context, song models.SongFilter) (models.SongList, error) {
 	const op = "service.music.GetSongs"
 
 	log := m.log.With(
@@ -43,20 +84,72 @@ func (m *MusicService) GetSongs(ctx context.Context, song models.SongFilter) ([]
 
 	log.Info("getting songs")
 
+	if song.Page < 1 {
+		song.Page = 1
+	}
+
+	switch {
+	case song.PageSize < 1:
+		song.PageSize = DefaultPageSize
+	case song.PageSize > MaxPageSize:
+		song.PageSize = MaxPageSize
+	}
+
+	if song.Search != "" {
+		if song.SearchMode == "" {
+			song.SearchMode = models.SearchWebsearch
+		}
+
+		if song.SearchLanguage == "" {
+			song.SearchLanguage = DefaultSearchLanguage
+		}
+	}
+
 	songs, err := m.music.GetSongs(ctx, song)
 	if err != nil {
 		log.Error("failed to get songs")
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("got songs")
 
+	songs.Page, songs.PageSize = song.Page, song.PageSize
+
+	if needsSuggestion(song, songs) {
+		names, err := m.music.ClosestNames(ctx, song.BandName, song.SongTitle, song.Threshold())
+		if err != nil {
+			// The suggestion is a courtesy, the listing itself succeeded.
+			log.Warn("failed to find closest names", sl.Err(err))
+		} else if names != (models.DidYouMean{}) {
+			songs.DidYouMean = &names
+		}
+	}
+
 	return songs, nil
 }
 
 
-func (m *MusicService) GetTextSong(ctx context.Context, song models.SongLyrics) (string, error) {
+// needsSuggestion reports whether the first page of a listing by band or title found no song with
+// exactly that band and title. A fuzzy listing finds similar songs, but still gets a suggestion
+// when none is exact.
+func needsSuggestion(filter models.SongFilter, songs models.SongList) bool {
+	if filter.BandName == "" && filter.SongTitle == "" || filter.Page > 1 || filter.Cursor != "" {
+		return false
+	}
+
+	for _, song := range songs.Songs {
+		if (filter.BandName == "" || strings.EqualFold(song.BandName, filter.BandName)) &&
+			(filter.SongTitle == "" || strings.EqualFold(song.SongTitle, filter.SongTitle)) {
+			return false
+		}
+	}
+
+	return len(songs.Songs) == 0 || filter.Fuzzy
+}
+
+
+func (m *MusicService) GetTextSong(ctx context.Context, song models.SongLyrics) (models.Verse, error) {
 	const op = "service.music.GetTextSong"
 
 	log := m.log.With(
@@ -67,11 +160,24 @@ func (m *MusicService) GetTextSong(ctx context.Context, song models.SongLyrics)
 
 	log.Info("getting text of song")
 
+	if song.Lang != "" {
+		verses, err := m.textIn(ctx, song)
+		if err != nil {
+			return models.Verse{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if song.Verse < 1 || song.Verse > len(verses) {
+			return models.Verse{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+		}
+
+		return verses[song.Verse-1], nil
+	}
+
 	verse, err := m.music.GetTextSong(ctx, song)
 	if err != nil {
 		log.Error("failed to get text of song")
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.Verse{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("got text of song")
@@ -79,6 +185,99 @@ func (m *MusicService) GetTextSong(ctx context.Context, song models.SongLyrics)
 	return verse, nil
 }
 
+// GetTextVerses returns a page of the verses in the requested range of the song's lyrics. A range
+// starting past the last verse is not found, one ending past it stops at the last verse.
+func (m *MusicService) GetTextVerses(ctx context.Context, song models.SongLyrics) (models.VersePage, error) {
+	const op = "service.music.GetTextVerses"
+
+	span, err := lyrics.ParseRange(song.Verses)
+	if err != nil {
+		return models.VersePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var verses []models.Verse
+	if song.Lang != "" {
+		verses, err = m.textIn(ctx, song)
+	} else {
+		verses, err = m.music.GetTextVerses(ctx, song)
+	}
+
+	if err != nil {
+		return models.VersePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if span.To == 0 || span.To > len(verses) {
+		span.To = len(verses)
+	}
+
+	if span.From > span.To {
+		return models.VersePage{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	selected := sections(verses)[span.From-1 : span.To]
+
+	page := models.VersePage{Page: song.Page, PageSize: song.PageSize, Total: len(selected), Language: verses[0].Language}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+
+	switch {
+	case page.PageSize < 1:
+		page.PageSize = DefaultPageSize
+	case page.PageSize > MaxPageSize:
+		page.PageSize = MaxPageSize
+	}
+
+	// Pages past the end are empty. Comparing pages first keeps a huge page number from overflowing.
+	start := len(selected)
+	if page.Page-1 < (len(selected)+page.PageSize-1)/page.PageSize {
+		start = (page.Page - 1) * page.PageSize
+	}
+
+	end := min(start+page.PageSize, len(selected))
+	page.Verses, page.HasNext = selected[start:end], end < len(selected)
+
+	return page, nil
+}
+
+// textIn returns the verses of the song in song.Lang: the original lyrics when they are in that
+// language, the translation otherwise. Translated verses are aligned with the original ones by
+// number and take their kind, as a translation need not repeat a chorus word for word.
+func (m *MusicService) textIn(ctx context.Context, song models.SongLyrics) ([]models.Verse, error) {
+	language, err := langtag.Canonical(song.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	song.Lang = language
+
+	original, err := m.music.GetTextVerses(ctx, song)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(original) > 0 && original[0].Language == language {
+		return original, nil
+	}
+
+	translation, err := m.music.GetTextTranslation(ctx, song)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := lyrics.Parse(translation.Lyrics)
+
+	verses := make([]models.Verse, 0, len(parsed))
+	for i, verse := range parsed {
+		if i < len(original) {
+			verse.Kind = original[i].Kind
+		}
+
+		verses = append(verses, models.Verse{Number: i + 1, Kind: verse.Kind, Text: verse.Text, Total: len(parsed), Language: language})
+	}
+
+	return verses, nil
+}
 
 func (m *MusicService) DeleteSong(ctx context.Context, song models.Song) (int64, error) {
 	const op = "service.music.DeleteSong"
@@ -98,6 +297,8 @@ func (m *MusicService) DeleteSong(ctx context.Context, song models.Song) (int64,
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
+	m.namesChanged()
+
 	log.Info("song deleted")
 
 	return id, nil
@@ -115,6 +316,13 @@ func (m *MusicService) UpdateSong(ctx context.Context, songDetails models.Song)(
 
 	log.Info("updating song")
 
+	language, err := songLanguage(songDetails.Language)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	songDetails.Language = language
+
 	id, err := m.music.UpdateSong(ctx, songDetails)
 	if err != nil {
 		log.Error("failed to update song")
@@ -139,12 +347,19 @@ func (m *MusicService) AddNewSong(ctx context.Context, song models.Song) (int64,
 
 	log.Info("adding new song")
 
+	language, err := songLanguage(song.Language)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	song.Language = language
+
 	id, err := m.music.AddNewSong(ctx, song)
 	if err != nil {
-		if errors.Is(err, storage.ErrSongExists) {
+		if errors.Is(err, errs.ErrSongExists) {
 			log.Warn("song already exists", sl.Err(err))
 
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrSongExists)
+			return 0, fmt.Errorf("%s: %w", op, err)
 		}
 
 		log.Error("failed to add song", sl.Err(err))
@@ -152,6 +367,8 @@ func (m *MusicService) AddNewSong(ctx context.Context, song models.Song) (int64,
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
+	m.namesChanged()
+
 	log.Info("song added")
 
 	return id, nil
@@ -0,0 +1,276 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+func (m *MusicService) GetSong(ctx context.Context, id int64) (models.Song, error) {
+	const op = "service.music.GetSong"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.Int64("id", id),
+	)
+
+	song, err := m.music.GetSong(ctx, id)
+	if err != nil {
+		log.Error("failed to get song", sl.Err(err))
+
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return song, nil
+}
+
+func (m *MusicService) ReplaceSong(ctx context.Context, id int64, song models.Song) (models.Song, error) {
+	const op = "service.music.ReplaceSong"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.Int64("id", id),
+	)
+
+	log.Info("replacing song")
+
+	language, err := songLanguage(song.Language)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	song.Language = language
+
+	replaced, err := m.music.ReplaceSong(ctx, id, song)
+	if err != nil {
+		log.Error("failed to replace song", sl.Err(err))
+
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.namesChanged()
+
+	log.Info("song replaced")
+
+	return replaced, nil
+}
+
+// PatchSong changes only the fields set in the patch, keeping the rest of the stored song.
+func (m *MusicService) PatchSong(ctx context.Context, id int64, patch models.SongPatch) (models.Song, error) {
+	const op = "service.music.PatchSong"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.Int64("id", id),
+	)
+
+	log.Info("patching song")
+
+	// An empty language clears it, like null does.
+	if patch.Language.Set && !patch.Language.Null {
+		language, err := songLanguage(patch.Language.Value)
+		if err != nil {
+			return models.Song{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		patch.Language = models.PatchField{Set: true, Null: language == "", Value: language}
+	}
+
+	patched, err := m.music.PatchSong(ctx, id, patch)
+	if err != nil {
+		log.Error("failed to patch song", sl.Err(err))
+
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.namesChanged()
+
+	log.Info("song patched")
+
+	return patched, nil
+}
+
+// songLanguage brings the original language of a song to its canonical BCP 47 spelling. An empty
+// language is left empty, as the language of a song is optional.
+func songLanguage(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	language, err := langtag.Canonical(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errs.ErrInvalidSong, err)
+	}
+
+	return language, nil
+}
+
+func (m *MusicService) DeleteSongByID(ctx context.Context, id int64) error {
+	const op = "service.music.DeleteSongByID"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.Int64("id", id),
+	)
+
+	log.Info("deleting song")
+
+	if err := m.music.DeleteSongByID(ctx, id); err != nil {
+		log.Error("failed to delete song", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.namesChanged()
+
+	log.Info("song deleted")
+
+	return nil
+}
+
+// GetLyrics returns the whole text of the song. A song without lyrics is reported as not found.
+func (m *MusicService) GetLyrics(ctx context.Context, id int64) (string, error) {
+	const op = "service.music.GetLyrics"
+
+	song, err := m.music.GetSong(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if song.Lyrics == "" {
+		return "", fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return song.Lyrics, nil
+}
+
+// GetVerse returns the n-th verse of the song, counting from one.
+func (m *MusicService) GetVerse(ctx context.Context, id int64, n int) (models.Verse, error) {
+	const op = "service.music.GetVerse"
+
+	verse, err := m.music.GetVerse(ctx, id, n)
+	if err != nil {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return verse, nil
+}
+
+// GetStructure returns the sections of the song's lyrics in order, pointing repeated sections at
+// their first occurrence.
+func (m *MusicService) GetStructure(ctx context.Context, id int64) (models.Structure, error) {
+	const op = "service.music.GetStructure"
+
+	verses, err := m.music.GetVerses(ctx, id)
+	if err != nil {
+		return models.Structure{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Structure{SongID: id, Sections: sections(verses)}, nil
+}
+
+// sections turns verses into sections, pointing repeated ones at their first occurrence.
+func sections(verses []models.Verse) []models.Section {
+	texts := make([]string, 0, len(verses))
+	for _, verse := range verses {
+		texts = append(texts, verse.Text)
+	}
+
+	repeats := lyrics.Repeats(texts)
+
+	sections := make([]models.Section, 0, len(verses))
+	for i, verse := range verses {
+		sections = append(sections, models.Section{
+			Number:   verse.Number,
+			Kind:     verse.Kind,
+			Text:     verse.Text,
+			RepeatOf: repeats[i],
+		})
+	}
+
+	return sections
+}
+
+// FindVerses returns the lines containing a phrase, with the verse each one is in. Storage narrows
+// the songs down by their lyrics, which are then split into verses the way GetTextSong does it.
+// A song storage matched only across a line break or inside a marker has no such line, so it is
+// skipped and the page is filled up from the songs after it.
+func (m *MusicService) FindVerses(ctx context.Context, query models.VerseQuery) (models.VerseMatches, error) {
+	const op = "service.music.FindVerses"
+
+	log := m.log.With(
+		slog.String("op", op),
+		slog.String("phrase", query.Phrase),
+	)
+
+	pageSize := query.PageSize
+	switch {
+	case pageSize < 1:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+
+	matches := models.VerseMatches{Matches: []models.VerseMatch{}}
+	found := 0
+
+	for after := query.Cursor; ; {
+		songs, err := m.music.GetSongs(ctx, models.SongFilter{
+			BandName:    query.BandName,
+			Lyrics:      query.Phrase,
+			LyricsMatch: models.MatchIContains,
+			Cursor:      after,
+			Page:        1,
+			PageSize:    pageSize,
+		})
+		if err != nil {
+			log.Error("failed to find songs", sl.Err(err))
+
+			return models.VerseMatches{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for i, song := range songs.Songs {
+			hits := lyrics.Find(lyrics.Normalize(song.Lyrics), query.Phrase)
+			if len(hits) == 0 {
+				continue
+			}
+
+			for _, hit := range hits {
+				matches.Matches = append(matches.Matches, models.VerseMatch{
+					SongID:    song.ID,
+					BandName:  song.BandName,
+					SongTitle: song.SongTitle,
+					Verse:     hit.Verse,
+					Line:      hit.Line,
+					Text:      hit.Text,
+				})
+			}
+
+			found++
+			if found < pageSize {
+				continue
+			}
+
+			// The page is full: the next one starts after this song, in the id order storage
+			// lists songs in when no sort is given.
+			if i < len(songs.Songs)-1 || songs.NextCursor != "" {
+				matches.NextCursor = cursor.Encode(cursor.Cursor{ID: song.ID})
+			}
+
+			return matches, nil
+		}
+
+		if songs.NextCursor == "" {
+			return matches, nil
+		}
+
+		after = songs.NextCursor
+	}
+}
@@ -0,0 +1,82 @@
+package music
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/storage/memory"
+)
+
+func TestFindVerses(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	for _, song := range []models.Song{
+		{BandName: "Muse", SongTitle: "Starlight", Lyrics: "Far away\nthis ship\n\nfar away"},
+		{BandName: "Muse", SongTitle: "Marker", Lyrics: "[Verse 2: far away]\nnear"},
+		{BandName: "Muse", SongTitle: "Uprising", Lyrics: "so far away from home"},
+		{BandName: "Muse", SongTitle: "Hysteria", Lyrics: "it's bugging me\r\n\r\n\r\nFAR AWAY"},
+		{BandName: "Muse", SongTitle: "Outro", Lyrics: "near\n\n[Outro: Far Away]"},
+		{BandName: "Placebo", SongTitle: "Bitter End", Lyrics: "far away"},
+		{BandName: "Muse", SongTitle: "Instrumental"},
+	} {
+		if _, err := store.AddNewSong(ctx, song); err != nil {
+			t.Fatalf("AddNewSong: %v", err)
+		}
+	}
+
+	service := New(store, nil, slog.New(slog.DiscardHandler))
+
+	type match struct {
+		title       string
+		verse, line int
+	}
+
+	var pages [][]match
+	query := models.VerseQuery{Phrase: "far away", PageSize: 2}
+
+	for range 5 {
+		got, err := service.FindVerses(ctx, query)
+		if err != nil {
+			t.Fatalf("FindVerses: %v", err)
+		}
+
+		var page []match
+		for _, m := range got.Matches {
+			page = append(page, match{m.SongTitle, m.Verse, m.Line})
+		}
+
+		pages = append(pages, page)
+
+		if got.NextCursor == "" {
+			break
+		}
+
+		query.Cursor = got.NextCursor
+	}
+
+	// Marker and Outro match the phrase only inside a marker and are skipped without using up a
+	// place: the first page ends in the middle of the second batch from storage, and the last page
+	// has no cursor.
+	want := [][]match{
+		{{"Starlight", 1, 1}, {"Starlight", 2, 1}, {"Uprising", 1, 1}},
+		{{"Hysteria", 2, 1}, {"Bitter End", 1, 1}},
+	}
+
+	if !slices.EqualFunc(pages, want, slices.Equal) {
+		t.Fatalf("pages: got %v, want %v", pages, want)
+	}
+
+	got, err := service.FindVerses(ctx, models.VerseQuery{Phrase: "far away", BandName: "Placebo"})
+	if err != nil || len(got.Matches) != 1 || got.NextCursor != "" {
+		t.Fatalf("FindVerses by band: got %+v, %v", got, err)
+	}
+
+	got, err = service.FindVerses(ctx, models.VerseQuery{Phrase: "outro"})
+	if err != nil || len(got.Matches) != 0 || got.NextCursor != "" {
+		t.Fatalf("FindVerses of a marker only: got %+v, %v, want no matches", got, err)
+	}
+}
@@ -0,0 +1,113 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lrc"
+)
+
+// ImportLRC parses LRC lyrics and stores them as the synced lyrics of the song, replacing any it
+// had. The plain lyrics of the song are left alone.
+func (m *MusicService) ImportLRC(ctx context.Context, id int64, text string) ([]models.SyncedLine, error) {
+	const op = "service.music.ImportLRC"
+
+	parsed, err := lrc.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	lines := make([]models.SyncedLine, 0, len(parsed))
+	for i, line := range parsed {
+		lines = append(lines, models.SyncedLine{Number: i + 1, TimeMs: line.Time.Milliseconds(), Text: line.Text})
+	}
+
+	if err := m.music.ReplaceSyncedLyrics(ctx, id, lines); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.log.With(slog.String("op", op), slog.Int64("id", id)).Info("imported synced lyrics", slog.Int("lines", len(lines)))
+
+	return lines, nil
+}
+
+func (m *MusicService) GetSyncedLyrics(ctx context.Context, id int64) ([]models.SyncedLine, error) {
+	const op = "service.music.GetSyncedLyrics"
+
+	lines, err := m.music.GetSyncedLyrics(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return lines, nil
+}
+
+// ExportSynced writes the synced lyrics of the song as LRC, tagged with the band and title, or as
+// WebVTT.
+func (m *MusicService) ExportSynced(ctx context.Context, id int64, format models.SyncedFormat) (string, error) {
+	const op = "service.music.ExportSynced"
+
+	song, err := m.music.GetSong(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	lines, err := m.music.GetSyncedLyrics(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch format {
+	case models.SyncedLRC:
+		return lrc.Format(song.BandName, song.SongTitle, lrcLines(lines)), nil
+	case models.SyncedVTT:
+		return lrc.VTT(lrcLines(lines)), nil
+	default:
+		return "", fmt.Errorf("%s: unsupported format %q", op, format)
+	}
+}
+
+// SyncedAt returns the line of the song sung at t and the one after it.
+func (m *MusicService) SyncedAt(ctx context.Context, id int64, t time.Duration) (models.SyncedPosition, error) {
+	const op = "service.music.SyncedAt"
+
+	lines, err := m.music.GetSyncedLyrics(ctx, id)
+	if err != nil {
+		return models.SyncedPosition{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	position := models.SyncedPosition{TimeMs: t.Milliseconds()}
+
+	i := lrc.At(lrcLines(lines), t)
+	if i >= 0 {
+		position.Line = &lines[i]
+	}
+
+	if i+1 < len(lines) {
+		position.Next = &lines[i+1]
+	}
+
+	return position, nil
+}
+
+func (m *MusicService) DeleteSyncedLyrics(ctx context.Context, id int64) error {
+	const op = "service.music.DeleteSyncedLyrics"
+
+	if err := m.music.DeleteSyncedLyrics(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func lrcLines(lines []models.SyncedLine) []lrc.Line {
+	converted := make([]lrc.Line, 0, len(lines))
+	for _, line := range lines {
+		converted = append(converted, lrc.Line{Time: time.Duration(line.TimeMs) * time.Millisecond, Text: line.Text})
+	}
+
+	return converted
+}
@@ -0,0 +1,73 @@
+package music
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/langtag"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+// SaveTranslation adds or replaces the translation of the song's lyrics to a BCP 47 language.
+// created reports whether the song had no translation to the language before.
+func (m *MusicService) SaveTranslation(ctx context.Context, id int64, language, text string) (models.Translation, bool, error) {
+	const op = "service.music.SaveTranslation"
+
+	language, err := langtag.Canonical(language)
+	if err != nil {
+		return models.Translation{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	created, err := m.music.SaveTranslation(ctx, id, language, text)
+	if err != nil {
+		return models.Translation{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m.log.With(slog.String("op", op), slog.Int64("id", id)).Info("saved translation", slog.String("language", language), slog.Bool("created", created))
+
+	return models.Translation{SongID: id, Language: language, Lyrics: lyrics.Normalize(text)}, created, nil
+}
+
+func (m *MusicService) GetTranslation(ctx context.Context, id int64, language string) (models.Translation, error) {
+	const op = "service.music.GetTranslation"
+
+	language, err := langtag.Canonical(language)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	translation, err := m.music.GetTranslation(ctx, id, language)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return translation, nil
+}
+
+func (m *MusicService) GetTranslations(ctx context.Context, id int64) ([]models.Translation, error) {
+	const op = "service.music.GetTranslations"
+
+	translations, err := m.music.GetTranslations(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return translations, nil
+}
+
+func (m *MusicService) DeleteTranslation(ctx context.Context, id int64, language string) error {
+	const op = "service.music.DeleteTranslation"
+
+	language, err := langtag.Canonical(language)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := m.music.DeleteTranslation(ctx, id, language); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
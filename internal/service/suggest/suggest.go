@@ -0,0 +1,162 @@
+// Package suggest completes band and song names for type-ahead.
+package suggest
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/api/logger/sl"
+	"github.com/stepan41k/Testovoe/internal/lib/fold"
+)
+
+const DefaultLimit = 10
+
+type Names interface {
+	Names(ctx context.Context, kind models.NameKind) (names []models.Name, err error)
+}
+
+type name struct {
+	models.Name
+	folded string
+}
+
+type entry struct {
+	names   []name
+	expires time.Time
+}
+
+// Suggester answers from an in-process copy of all names of a kind, reloaded from storage once it
+// is older than the TTL or invalidated by a write. Songs written elsewhere, such as by another
+// instance, show up in suggestions after at most one TTL.
+type Suggester struct {
+	names Names
+	ttl   time.Duration
+	log   *slog.Logger
+
+	mu         sync.Mutex
+	cache      map[models.NameKind]entry
+	generation uint64
+}
+
+func New(names Names, ttl time.Duration, log *slog.Logger) *Suggester {
+	return &Suggester{
+		names: names,
+		ttl:   ttl,
+		log:   log,
+		cache: make(map[models.NameKind]entry),
+	}
+}
+
+// Suggest returns the names containing the query, case- and accent-insensitively. Names starting
+// with the query come before the ones containing it elsewhere, each group ordered by the number of
+// songs or alphabetically. A query that is blank once folded matches nothing.
+func (s *Suggester) Suggest(ctx context.Context, query models.SuggestQuery) ([]models.Name, error) {
+	const op = "service.suggest.Suggest"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("kind", string(query.Kind)),
+	)
+
+	names, err := s.load(ctx, query.Kind)
+	if err != nil {
+		log.Error("failed to load names", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	key := fold.String(strings.TrimSpace(query.Query))
+	if key == "" {
+		return []models.Name{}, nil
+	}
+
+	var prefix, infix []name
+	for _, n := range names {
+		switch {
+		case strings.HasPrefix(n.folded, key):
+			prefix = append(prefix, n)
+		case strings.Contains(n.folded, key):
+			infix = append(infix, n)
+		}
+	}
+
+	order := compareNames(query.Order)
+	slices.SortFunc(prefix, order)
+	slices.SortFunc(infix, order)
+
+	limit := query.Limit
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+
+	suggestions := make([]models.Name, 0, min(limit, len(prefix)+len(infix)))
+	for _, n := range append(prefix, infix...) {
+		if len(suggestions) == limit {
+			break
+		}
+
+		suggestions = append(suggestions, n.Name)
+	}
+
+	return suggestions, nil
+}
+
+// Invalidate drops the cached names, so that the next suggestion reloads them from storage.
+func (s *Suggester) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.cache)
+	s.generation++
+}
+
+func (s *Suggester) load(ctx context.Context, kind models.NameKind) ([]name, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[kind]
+	generation := s.generation
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		return cached.names, nil
+	}
+
+	loaded, err := s.names.Names(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]name, 0, len(loaded))
+	for _, n := range loaded {
+		names = append(names, name{Name: n, folded: fold.String(n.Value)})
+	}
+
+	// Names loaded before an invalidation may miss the write behind it and are not kept.
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[kind] = entry{names: names, expires: time.Now().Add(s.ttl)}
+	}
+	s.mu.Unlock()
+
+	return names, nil
+}
+
+func compareNames(order models.SuggestOrder) func(a, b name) int {
+	alphabetical := func(a, b name) int {
+		return cmp.Or(strings.Compare(a.folded, b.folded), strings.Compare(a.Value, b.Value))
+	}
+
+	if order == models.SuggestAlpha {
+		return alphabetical
+	}
+
+	return func(a, b name) int {
+		return cmp.Or(cmp.Compare(b.Songs, a.Songs), alphabetical(a, b))
+	}
+}
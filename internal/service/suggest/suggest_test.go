@@ -0,0 +1,178 @@
+package suggest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	musicService "github.com/stepan41k/Testovoe/internal/service/music"
+	"github.com/stepan41k/Testovoe/internal/storage/memory"
+)
+
+func TestSuggest(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	for _, song := range []models.Song{
+		{BandName: "Muse", SongTitle: "Starlight"},
+		{BandName: "Muse", SongTitle: "Hysteria"},
+		{BandName: "Museum", SongTitle: "Ghost"},
+		{BandName: "Amused", SongTitle: "Far"},
+		{BandName: "Amused", SongTitle: "Near"},
+		{BandName: "Amused", SongTitle: "Home"},
+		{BandName: "Mötley Crüe", SongTitle: "Home Sweet Home"},
+		{BandName: "Мумий Тролль", SongTitle: "Утекай"},
+		{BandName: "Placebo", SongTitle: "Bitter End"},
+	} {
+		if _, err := store.AddNewSong(ctx, song); err != nil {
+			t.Fatalf("AddNewSong: %v", err)
+		}
+	}
+
+	suggester := New(store, time.Hour, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		name  string
+		query models.SuggestQuery
+		want  []string
+	}{
+		{name: "prefix before infix, by songs", query: models.SuggestQuery{Query: "mus", Kind: models.NameBand}, want: []string{"Muse", "Museum", "Amused"}},
+		{name: "prefix before infix, alphabetical", query: models.SuggestQuery{Query: "mus", Kind: models.NameBand, Order: models.SuggestAlpha}, want: []string{"Muse", "Museum", "Amused"}},
+		{name: "infix by songs", query: models.SuggestQuery{Query: "e", Kind: models.NameBand}, want: []string{"Amused", "Muse", "Mötley Crüe", "Museum", "Placebo"}},
+		{name: "infix alphabetical", query: models.SuggestQuery{Query: "e", Kind: models.NameBand, Order: models.SuggestAlpha}, want: []string{"Amused", "Mötley Crüe", "Muse", "Museum", "Placebo"}},
+		{name: "case", query: models.SuggestQuery{Query: "PLACE", Kind: models.NameBand}, want: []string{"Placebo"}},
+		{name: "accents", query: models.SuggestQuery{Query: "motley crue", Kind: models.NameBand}, want: []string{"Mötley Crüe"}},
+		{name: "accented query", query: models.SuggestQuery{Query: "Crüe", Kind: models.NameBand}, want: []string{"Mötley Crüe"}},
+		{name: "cyrillic", query: models.SuggestQuery{Query: "мумий", Kind: models.NameBand}, want: []string{"Мумий Тролль"}},
+		{name: "songs", query: models.SuggestQuery{Query: "home", Kind: models.NameSong}, want: []string{"Home", "Home Sweet Home"}},
+		{name: "limit", query: models.SuggestQuery{Query: "e", Kind: models.NameBand, Limit: 2}, want: []string{"Amused", "Muse"}},
+		{name: "spaces around", query: models.SuggestQuery{Query: "  muse ", Kind: models.NameBand, Limit: 1}, want: []string{"Muse"}},
+		{name: "no match", query: models.SuggestQuery{Query: "zzz", Kind: models.NameBand}, want: []string{}},
+		{name: "blank", query: models.SuggestQuery{Query: " \t", Kind: models.NameBand}, want: []string{}},
+		{name: "accent only", query: models.SuggestQuery{Query: "\u0301", Kind: models.NameBand}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := suggester.Suggest(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Suggest: %v", err)
+			}
+
+			values := []string{}
+			for _, name := range got {
+				values = append(values, name.Value)
+			}
+
+			if !slices.Equal(values, tt.want) {
+				t.Fatalf("Suggest(%+v): got %v, want %v", tt.query, values, tt.want)
+			}
+		})
+	}
+
+	got, _ := suggester.Suggest(ctx, models.SuggestQuery{Query: "amused", Kind: models.NameBand})
+	if want := []models.Name{{Value: "Amused", Songs: 3}}; !slices.Equal(got, want) {
+		t.Fatalf("Suggest song count: got %+v, want %+v", got, want)
+	}
+}
+
+func TestSuggestCache(t *testing.T) {
+	ctx := context.Background()
+	store := &countingNames{store: memory.New()}
+
+	suggester := New(store, time.Hour, slog.New(slog.DiscardHandler))
+	music := musicService.New(store.store, suggester, slog.New(slog.DiscardHandler))
+
+	suggest := func() []models.Name {
+		t.Helper()
+
+		got, err := suggester.Suggest(ctx, models.SuggestQuery{Query: "mus", Kind: models.NameBand})
+		if err != nil {
+			t.Fatalf("Suggest: %v", err)
+		}
+
+		return got
+	}
+
+	id, err := music.AddNewSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	if got := suggest(); !slices.Equal(got, []models.Name{{Value: "Muse", Songs: 1}}) {
+		t.Fatalf("Suggest: got %+v", got)
+	}
+
+	suggest()
+	if store.loads() != 1 {
+		t.Fatalf("Suggest twice: got %d loads, want the second answered from the cache", store.loads())
+	}
+
+	// A write straight to storage is not seen until the cache expires.
+	if _, err := store.store.AddNewSong(ctx, models.Song{BandName: "Museum", SongTitle: "Ghost"}); err != nil {
+		t.Fatalf("AddNewSong: %v", err)
+	}
+
+	if got := suggest(); len(got) != 1 {
+		t.Fatalf("Suggest from the cache: got %+v, want the names cached before", got)
+	}
+
+	// Writes through the music service invalidate the cache.
+	if _, err := music.PatchSong(ctx, id, models.SongPatch{BandName: models.PatchField{Set: true, Value: "Muse UK"}}); err != nil {
+		t.Fatalf("PatchSong: %v", err)
+	}
+
+	if got := suggest(); !slices.Equal(got, []models.Name{{Value: "Muse UK", Songs: 1}, {Value: "Museum", Songs: 1}}) {
+		t.Fatalf("Suggest after a patch: got %+v", got)
+	}
+
+	if err := music.DeleteSongByID(ctx, id); err != nil {
+		t.Fatalf("DeleteSongByID: %v", err)
+	}
+
+	if got := suggest(); !slices.Equal(got, []models.Name{{Value: "Museum", Songs: 1}}) {
+		t.Fatalf("Suggest after a delete: got %+v", got)
+	}
+
+	expiring := New(store, time.Millisecond, slog.New(slog.DiscardHandler))
+	before := store.loads()
+
+	for range 2 {
+		time.Sleep(2 * time.Millisecond)
+
+		if _, err := expiring.Suggest(ctx, models.SuggestQuery{Query: "mus", Kind: models.NameBand}); err != nil {
+			t.Fatalf("Suggest: %v", err)
+		}
+	}
+
+	if got := store.loads() - before; got != 2 {
+		t.Fatalf("Suggest after the TTL: got %d loads, want 2", got)
+	}
+}
+
+// countingNames counts the loads that reach storage.
+type countingNames struct {
+	store *memory.MStorage
+
+	mu    sync.Mutex
+	count int
+}
+
+func (c *countingNames) Names(ctx context.Context, kind models.NameKind) ([]models.Name, error) {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+
+	return c.store.Names(ctx, kind)
+}
+
+func (c *countingNames) loads() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.count
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/match"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+	"github.com/stepan41k/Testovoe/internal/lib/trigram"
+)
+
+func songMatcher(filter models.SongFilter) (func(item *record) bool, error) {
+	var predicates []func(item *record) bool
+
+	switch {
+	case filter.BandName != "" && filter.Fuzzy:
+		predicates = append(predicates, func(item *record) bool {
+			return trigram.Similarity(item.band, filter.BandName) >= filter.Threshold()
+		})
+	case filter.BandName != "":
+		predicates = append(predicates, func(item *record) bool {
+			return match.String(filter.BandMatch, models.MatchExact, item.band, filter.BandName)
+		})
+	}
+
+	switch {
+	case filter.SongTitle != "" && filter.Fuzzy:
+		predicates = append(predicates, func(item *record) bool {
+			return trigram.Similarity(item.title, filter.SongTitle) >= filter.Threshold()
+		})
+	case filter.SongTitle != "":
+		predicates = append(predicates, func(item *record) bool {
+			return match.String(filter.TitleMatch, models.MatchExact, item.title, filter.SongTitle)
+		})
+	}
+
+	if filter.Lyrics != "" {
+		predicates = append(predicates, func(item *record) bool {
+			return item.lyrics != "" && match.String(filter.LyricsMatch, models.MatchContains, item.lyrics, filter.Lyrics)
+		})
+	}
+
+	release, err := releasedate.FilterRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !release.Empty() {
+		predicates = append(predicates, func(item *record) bool {
+			return item.release != nil && release.Contains(*item.release)
+		})
+	}
+
+	if filter.HasLink != nil {
+		predicates = append(predicates, func(item *record) bool {
+			return (item.link != "") == *filter.HasLink
+		})
+	}
+
+	return func(item *record) bool {
+		for _, predicate := range predicates {
+			if !predicate(item) {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/trigram"
+)
+
+// ClosestNames finds the existing band and title most similar to the given ones, for a "did you
+// mean" suggestion. A name is left empty when it was not asked for or nothing reaches threshold.
+func (s *MStorage) ClosestNames(ctx context.Context, band, title string, threshold float64) (models.DidYouMean, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var names models.DidYouMean
+	var bandScore, titleScore float64
+
+	for _, item := range s.records() {
+		if band != "" {
+			closer(&names.BandName, &bandScore, item.band, trigram.Similarity(item.band, band), threshold)
+		}
+
+		if title != "" {
+			closer(&names.SongTitle, &titleScore, item.title, trigram.Similarity(item.title, title), threshold)
+		}
+	}
+
+	return names, nil
+}
+
+// closer keeps candidate when it is more similar than the best name so far, or as similar and
+// alphabetically first, like the SQL backends order them.
+func closer(best *string, bestScore *float64, candidate string, score, threshold float64) {
+	if score < threshold {
+		return
+	}
+
+	if *best == "" || score > *bestScore || score == *bestScore && strings.Compare(candidate, *best) < 0 {
+		*best, *bestScore = candidate, score
+	}
+}
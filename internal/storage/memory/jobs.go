@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+const (
+	jobPending = "pending"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobDead    = "dead"
+)
+
+func (s *MStorage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	var next *jobRecord
+	for _, job := range s.jobs {
+		if job.state != jobPending && job.state != jobRunning || job.runAt.After(now) {
+			continue
+		}
+
+		if next == nil || job.runAt.Before(next.runAt) || job.runAt.Equal(next.runAt) && job.id < next.id {
+			next = job
+		}
+	}
+
+	if next == nil {
+		return models.EnrichmentJob{}, errs.ErrNoJobs
+	}
+
+	item := s.songs[next.songID]
+
+	next.state = jobRunning
+	next.attempts++
+	next.runAt = now.Add(lease)
+
+	return models.EnrichmentJob{
+		ID:        next.id,
+		SongID:    item.id,
+		BandName:  item.band,
+		SongTitle: item.title,
+		Attempts:  next.attempts,
+	}, nil
+}
+
+// CompleteEnrichmentJob marks the job done and fills in the details the song still lacks. Details
+// the client already gave are kept, and blank upstream values leave a field empty.
+func (s *MStorage) CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) error {
+	const op = "storage.memory.jobs.CompleteEnrichmentJob"
+
+	var release *time.Time
+	if details.ReleaseDate != "" {
+		date, err := parseRelease(details.ReleaseDate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		release = &date
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, errs.ErrSongNotFound)
+	}
+
+	job.state = jobDone
+	job.lastError = ""
+
+	item := s.songs[job.songID]
+	if item.release == nil {
+		item.release = release
+	}
+
+	if item.lyrics == "" {
+		item.setLyrics(details.Lyrics)
+	}
+
+	if item.link == "" {
+		item.link = details.Link
+	}
+
+	item.status = models.SongStatusEnriched
+	item.updated = time.Now()
+
+	return nil
+}
+
+func (s *MStorage) RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[jobID]; ok {
+		job.state = jobPending
+		job.runAt = time.Now().Add(delay)
+		job.lastError = reason
+	}
+
+	return nil
+}
+
+// FailEnrichmentJob moves the job to the dead letter state. Its song is marked failed unless the
+// client already gave every detail, in which case nothing is missing and it counts as enriched.
+func (s *MStorage) FailEnrichmentJob(ctx context.Context, jobID int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.jobs[jobID]; ok {
+		job.state = jobDead
+		job.lastError = reason
+
+		item := s.songs[job.songID]
+		item.status = models.SongStatusFailed
+		if item.release != nil && item.lyrics != "" && item.link != "" {
+			item.status = models.SongStatusEnriched
+		}
+
+		item.updated = time.Now()
+	}
+
+	return nil
+}
+
+func (s *MStorage) RequeueFailedJobs(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	var count int64
+	for _, job := range s.jobs {
+		if job.state != jobDead {
+			continue
+		}
+
+		job.state = jobPending
+		job.attempts = 0
+		job.runAt = now
+		job.lastError = ""
+
+		item := s.songs[job.songID]
+		item.status = models.SongStatusPending
+		item.updated = now
+
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+)
+
+type record struct {
+	id      int64
+	band    string
+	title   string
+	release *time.Time
+	lyrics  string
+	link    string
+	status  string
+	updated time.Time
+	verses  []lyrics.Verse
+	synced  []models.SyncedLine
+
+	// language is the original language of the lyrics, translations map other languages to lyrics.
+	language     string
+	translations map[string]string
+
+	// rank and snippet are only set on the copies GetSongs makes for a lyrics search.
+	rank    float64
+	snippet string
+}
+
+type jobRecord struct {
+	id        int64
+	songID    int64
+	state     string
+	attempts  int
+	lastError string
+	runAt     time.Time
+}
+
+type MStorage struct {
+	mu         sync.RWMutex
+	songs      map[int64]*record
+	jobs       map[int64]*jobRecord
+	lastSongID int64
+	lastJobID  int64
+}
+
+func New() *MStorage {
+	return &MStorage{
+		songs: make(map[int64]*record),
+		jobs:  make(map[int64]*jobRecord),
+	}
+}
+
+func Close(storage *MStorage) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	storage.songs = make(map[int64]*record)
+	storage.jobs = make(map[int64]*jobRecord)
+}
+
+func parseRelease(value string) (time.Time, error) {
+	date, err := releasedate.Parse(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", errs.ErrInvalidSong, err)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+func (s *MStorage) GetSongs(ctx context.Context, song models.SongFilter) (models.SongList, error) {
+	const op = "storage.memory.music.GetSongs"
+
+	matches, err := songMatcher(song)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys, err := sorting.ForSearch(song.Sort, song.Ranked())
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, err := cursor.Decode(song.Cursor, keys)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	limit, offset := song.PageSize, (song.Page-1)*song.PageSize
+	if limit < 0 || offset < 0 {
+		return models.SongList{}, fmt.Errorf("%s: negative limit or offset", op)
+	}
+
+	var boundary *record
+	if after != nil {
+		boundary, err = cursorRecord(after, keys)
+		if err != nil {
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		offset = 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ordered := s.records()
+	if song.Ranked() {
+		ordered = rankRecords(ordered, song)
+	}
+
+	sortRecords(ordered, keys)
+
+	if after != nil && after.Backward {
+		slices.Reverse(ordered)
+	}
+
+	skip := offset
+
+	var total int64
+	var page []*record
+	for _, item := range ordered {
+		if !matches(item) {
+			continue
+		}
+
+		total++
+
+		if boundary != nil && !follows(item, boundary, keys, after.Backward) {
+			continue
+		}
+
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		if len(page) > limit {
+			if song.Count {
+				continue
+			}
+
+			break
+		}
+
+		page = append(page, item)
+	}
+
+	page, next, prev := cursor.Paginate(page, limit, after, offset, keys, func(item *record) ([]string, int64) {
+		return cursorValues(item, keys), item.id
+	})
+
+	songs := make([]models.Song, 0, len(page))
+	for _, item := range page {
+		model := item.model()
+		model.Rank, model.Snippet = item.rank, item.snippet
+		songs = append(songs, model)
+	}
+
+	list := models.SongList{Songs: songs, NextCursor: next, PrevCursor: prev}
+	if song.Count {
+		list.Total = &total
+	}
+
+	return list, nil
+}
+
+func (s *MStorage) GetTextSong(ctx context.Context, song models.SongLyrics) (models.Verse, error) {
+	const op = "storage.memory.music.GetTextSong"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item := s.find(song.BandName, song.SongTitle)
+	if item == nil {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	verse, ok := item.verse(song.Verse)
+	if !ok {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	return verse, nil
+}
+
+// GetTextVerses returns every verse of the song found by band and title in order.
+func (s *MStorage) GetTextVerses(ctx context.Context, song models.SongLyrics) ([]models.Verse, error) {
+	const op = "storage.memory.music.GetTextVerses"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item := s.find(song.BandName, song.SongTitle)
+	if item == nil {
+		return nil, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	return item.allVerses(), nil
+}
+
+func (s *MStorage) DeleteSong(ctx context.Context, song models.Song) (int64, error) {
+	const op = "storage.memory.music.DeleteSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.find(song.BandName, song.SongTitle)
+	if item == nil {
+		return 0, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	s.deleteRecord(item.id)
+
+	return item.id, nil
+}
+
+// UpdateSong applies every non-empty field of the legacy update body to the song found by band and title.
+func (s *MStorage) UpdateSong(ctx context.Context, song models.Song) (int64, error) {
+	const op = "storage.memory.music.UpdateSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := s.find(song.BandName, song.SongTitle)
+	if item == nil {
+		if models.LegacyPatch(song).Empty() {
+			return 0, fmt.Errorf("%s: %w", op, errs.ErrNoChanges)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	if err := s.patchRecord(item, models.LegacyPatch(song)); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return item.id, nil
+}
+
+func (s *MStorage) AddNewSong(ctx context.Context, song models.Song) (int64, error) {
+	const op = "storage.memory.music.AddNewSong"
+
+	item := &record{
+		band:     song.BandName,
+		title:    song.SongTitle,
+		link:     song.Link,
+		status:   models.SongStatusPending,
+		updated:  time.Now(),
+		language: song.Language,
+	}
+
+	item.setLyrics(song.Lyrics)
+
+	if song.ReleaseDate != "" {
+		date, err := parseRelease(song.ReleaseDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		item.release = &date
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.find(song.BandName, song.SongTitle) != nil {
+		return 0, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongExists))
+	}
+
+	s.lastSongID++
+	item.id = s.lastSongID
+	s.songs[item.id] = item
+
+	s.lastJobID++
+	s.jobs[s.lastJobID] = &jobRecord{
+		id:     s.lastJobID,
+		songID: item.id,
+		state:  jobPending,
+		runAt:  time.Now(),
+	}
+
+	return item.id, nil
+}
+
+func (s *MStorage) find(band, title string) *record {
+	for _, item := range s.songs {
+		if item.band == band && item.title == title {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// deleteRecord removes a song together with its enrichment job, like the foreign key cascade does.
+func (s *MStorage) deleteRecord(songID int64) {
+	delete(s.songs, songID)
+
+	for id, job := range s.jobs {
+		if job.songID == songID {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *MStorage) records() []*record {
+	songs := make([]*record, 0, len(s.songs))
+	for _, item := range s.songs {
+		songs = append(songs, item)
+	}
+
+	return songs
+}
+
+func (item *record) model() models.Song {
+	song := models.Song{
+		ID:        item.id,
+		BandName:  item.band,
+		SongTitle: item.title,
+		Lyrics:    item.lyrics,
+		Link:      item.link,
+		Status:    item.status,
+		Language:  item.language,
+	}
+
+	if item.release != nil {
+		song.ReleaseDate = releasedate.Format(*item.release)
+	}
+
+	return song
+}
+
+// setLyrics stores normalized lyrics along with their verses, like the SQL backends keep them in song_verses.
+func (item *record) setLyrics(text string) {
+	item.lyrics = lyrics.Normalize(text)
+	item.verses = lyrics.Parse(item.lyrics)
+}
+
+// verse returns the n-th verse of the record, counting from one.
+func (item *record) verse(n int) (models.Verse, bool) {
+	if n < 1 || n > len(item.verses) {
+		return models.Verse{}, false
+	}
+
+	verse := item.verses[n-1]
+
+	return models.Verse{Number: n, Kind: verse.Kind, Text: verse.Text, Total: len(item.verses), Language: item.language}, true
+}
+
+// allVerses returns every verse of the record in order.
+func (item *record) allVerses() []models.Verse {
+	verses := make([]models.Verse, 0, len(item.verses))
+	for n := range item.verses {
+		verse, _ := item.verse(n + 1)
+		verses = append(verses, verse)
+	}
+
+	return verses
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// Names lists every distinct band or song title with the number of songs carrying it.
+func (s *MStorage) Names(ctx context.Context, kind models.NameKind) ([]models.Name, error) {
+	const op = "storage.memory.names.Names"
+
+	if kind != models.NameBand && kind != models.NameSong {
+		return nil, fmt.Errorf("%s: unknown name kind %q", op, kind)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, item := range s.songs {
+		if kind == models.NameBand {
+			counts[item.band]++
+		} else {
+			counts[item.title]++
+		}
+	}
+
+	names := make([]models.Name, 0, len(counts))
+	for value, songs := range counts {
+		names = append(names, models.Name{Value: value, Songs: songs})
+	}
+
+	slices.SortFunc(names, func(a, b models.Name) int {
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	return names, nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/fulltext"
+	"github.com/stepan41k/Testovoe/internal/lib/trigram"
+)
+
+// rankRecords returns ranked copies of the records: the score of a lyrics search, which also drops
+// the records that do not match it, plus the similarity of fuzzily matched band and title. There
+// is no stemming here, every search language behaves like "simple".
+func rankRecords(items []*record, filter models.SongFilter) []*record {
+	mode := filter.SearchMode
+	if mode == "" {
+		mode = models.SearchWebsearch
+	}
+
+	query := fulltext.Parse(fulltext.Mode(mode), filter.Search)
+
+	var ranked []*record
+	for _, item := range items {
+		if filter.Search != "" && !query.Match(item.lyrics) {
+			continue
+		}
+
+		copied := *item
+
+		if filter.Search != "" {
+			copied.rank, copied.snippet = query.Rank(item.lyrics), query.Headline(item.lyrics)
+		}
+
+		if filter.Fuzzy && filter.BandName != "" {
+			copied.rank += trigram.Similarity(item.band, filter.BandName)
+		}
+
+		if filter.Fuzzy && filter.SongTitle != "" {
+			copied.rank += trigram.Similarity(item.title, filter.SongTitle)
+		}
+
+		ranked = append(ranked, &copied)
+	}
+
+	return ranked
+}
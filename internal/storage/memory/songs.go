@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+func (s *MStorage) GetSong(ctx context.Context, id int64) (models.Song, error) {
+	const op = "storage.memory.songs.GetSong"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return models.Song{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return item.model(), nil
+}
+
+// ReplaceSong overwrites every editable field of the song. Empty fields clear the value.
+func (s *MStorage) ReplaceSong(ctx context.Context, id int64, song models.Song) (models.Song, error) {
+	const op = "storage.memory.songs.ReplaceSong"
+
+	var release *time.Time
+	if song.ReleaseDate != "" {
+		date, err := parseRelease(song.ReleaseDate)
+		if err != nil {
+			return models.Song{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		release = &date
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return models.Song{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	if other := s.find(song.BandName, song.SongTitle); other != nil && other.id != id {
+		return models.Song{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongExists))
+	}
+
+	item.band, item.title = song.BandName, song.SongTitle
+	item.release, item.link, item.language = release, song.Link, song.Language
+	item.setLyrics(song.Lyrics)
+	item.updated = time.Now()
+
+	return item.model(), nil
+}
+
+// PatchSong changes the fields set in the patch at once and returns the updated song.
+func (s *MStorage) PatchSong(ctx context.Context, id int64, patch models.SongPatch) (models.Song, error) {
+	const op = "storage.memory.songs.PatchSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		if patch.Empty() {
+			return models.Song{}, fmt.Errorf("%s: %w", op, errs.ErrNoChanges)
+		}
+
+		return models.Song{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	if err := s.patchRecord(item, patch); err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return item.model(), nil
+}
+
+// patchRecord validates the whole patch before touching the record, so a failed patch changes nothing.
+func (s *MStorage) patchRecord(item *record, patch models.SongPatch) error {
+	if patch.Empty() {
+		return errs.ErrNoChanges
+	}
+
+	band, title := item.band, item.title
+	if patch.BandName.Set {
+		band = patch.BandName.Value
+	}
+	if patch.SongTitle.Set {
+		title = patch.SongTitle.Value
+	}
+
+	if other := s.find(band, title); other != nil && other.id != item.id {
+		return errs.ByName(band, title, errs.ErrSongExists)
+	}
+
+	release := item.release
+	switch {
+	case patch.ReleaseDate.Null:
+		release = nil
+	case patch.ReleaseDate.Set:
+		date, err := parseRelease(patch.ReleaseDate.Value)
+		if err != nil {
+			return err
+		}
+
+		release = &date
+	}
+
+	item.band, item.title, item.release = band, title, release
+
+	if patch.Lyrics.Set {
+		item.setLyrics(patch.Lyrics.Value)
+	}
+	if patch.Link.Set {
+		item.link = patch.Link.Value
+	}
+	if patch.Language.Set {
+		item.language = patch.Language.Value
+	}
+
+	item.updated = time.Now()
+
+	return nil
+}
+
+func (s *MStorage) DeleteSongByID(ctx context.Context, id int64) error {
+	const op = "storage.memory.songs.DeleteSongByID"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[id]; !ok {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	s.deleteRecord(id)
+
+	return nil
+}
+
+// GetVerse returns the n-th verse of the song, counting from one.
+func (s *MStorage) GetVerse(ctx context.Context, id int64, n int) (models.Verse, error) {
+	const op = "storage.memory.songs.GetVerse"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	verse, ok := item.verse(n)
+	if !ok {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return verse, nil
+}
+
+// GetVerses returns every verse of the song in order. A song without lyrics has no verses.
+func (s *MStorage) GetVerses(ctx context.Context, id int64) ([]models.Verse, error) {
+	const op = "storage.memory.songs.GetVerses"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return item.allVerses(), nil
+}
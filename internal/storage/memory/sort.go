@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+const cursorDate = "2006-01-02"
+
+func sortRecords(songs []*record, keys []sorting.Key) {
+	slices.SortFunc(songs, func(a, b *record) int {
+		return compareRecords(a, b, keys)
+	})
+}
+
+func compareRecords(a, b *record, keys []sorting.Key) int {
+	for _, key := range keys {
+		if result := compareKey(a, b, key); result != 0 {
+			return result
+		}
+	}
+
+	return cmp.Compare(a.id, b.id)
+}
+
+// follows reports whether item comes after the cursor boundary in sort order, or before it for a backward cursor.
+func follows(item, boundary *record, keys []sorting.Key, backward bool) bool {
+	result := compareRecords(item, boundary, keys)
+	if backward {
+		return result < 0
+	}
+
+	return result > 0
+}
+
+// cursorValues renders the sort key values of a record for a cursor. A missing release date is an empty string.
+func cursorValues(item *record, keys []sorting.Key) []string {
+	values := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		switch key.Field {
+		case sorting.FieldBand:
+			values = append(values, item.band)
+		case sorting.FieldTitle:
+			values = append(values, item.title)
+		case sorting.FieldRelease:
+			if item.release == nil {
+				values = append(values, "")
+			} else {
+				values = append(values, item.release.Format(cursorDate))
+			}
+		case sorting.FieldUpdated:
+			values = append(values, item.updated.Format(time.RFC3339Nano))
+		case sorting.FieldRank:
+			values = append(values, strconv.FormatFloat(item.rank, 'g', -1, 64))
+		}
+	}
+
+	return values
+}
+
+// cursorRecord rebuilds the boundary record a cursor points at, so it can be compared like any other record.
+func cursorRecord(c *cursor.Cursor, keys []sorting.Key) (*record, error) {
+	item := &record{id: c.ID}
+
+	for i, key := range keys {
+		value := c.Values[i]
+
+		switch key.Field {
+		case sorting.FieldBand:
+			item.band = value
+		case sorting.FieldTitle:
+			item.title = value
+		case sorting.FieldRelease:
+			if value == "" {
+				continue
+			}
+
+			date, err := time.Parse(cursorDate, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", cursor.ErrInvalidCursor, err)
+			}
+
+			item.release = &date
+		case sorting.FieldUpdated:
+			updated, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", cursor.ErrInvalidCursor, err)
+			}
+
+			item.updated = updated
+		case sorting.FieldRank:
+			rank, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", cursor.ErrInvalidCursor, err)
+			}
+
+			item.rank = rank
+		}
+	}
+
+	return item, nil
+}
+
+// compareKey keeps songs without a release date last in both directions, like the SQL backends do.
+func compareKey(a, b *record, key sorting.Key) int {
+	var result int
+
+	switch key.Field {
+	case sorting.FieldBand:
+		result = strings.Compare(a.band, b.band)
+	case sorting.FieldTitle:
+		result = strings.Compare(a.title, b.title)
+	case sorting.FieldRelease:
+		switch {
+		case a.release == nil && b.release == nil:
+			return 0
+		case a.release == nil:
+			return 1
+		case b.release == nil:
+			return -1
+		}
+		result = a.release.Compare(*b.release)
+	case sorting.FieldUpdated:
+		result = a.updated.Compare(b.updated)
+	case sorting.FieldRank:
+		result = cmp.Compare(a.rank, b.rank)
+	}
+
+	if key.Desc {
+		return -result
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// ReplaceSyncedLyrics stores the synced lines of the song in place of the previous ones. Lines are
+// numbered in the order given.
+func (s *MStorage) ReplaceSyncedLyrics(ctx context.Context, id int64, lines []models.SyncedLine) error {
+	const op = "storage.memory.synced.ReplaceSyncedLyrics"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	item.synced = make([]models.SyncedLine, 0, len(lines))
+	for i, line := range lines {
+		line.Number = i + 1
+		item.synced = append(item.synced, line)
+	}
+
+	item.updated = time.Now()
+
+	return nil
+}
+
+// GetSyncedLyrics returns the synced lines of the song in order.
+func (s *MStorage) GetSyncedLyrics(ctx context.Context, id int64) ([]models.SyncedLine, error) {
+	const op = "storage.memory.synced.GetSyncedLyrics"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	case len(item.synced) == 0:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+	}
+
+	return slices.Clone(item.synced), nil
+}
+
+// DeleteSyncedLyrics removes the synced lines of the song, leaving its plain lyrics alone.
+func (s *MStorage) DeleteSyncedLyrics(ctx context.Context, id int64) error {
+	const op = "storage.memory.synced.DeleteSyncedLyrics"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	switch {
+	case !ok:
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	case len(item.synced) == 0:
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+	}
+
+	item.synced = nil
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+// SaveTranslation adds the translation of the song's lyrics to the language, or replaces the one
+// it has. created reports whether the song had no translation to the language before.
+func (s *MStorage) SaveTranslation(ctx context.Context, id int64, language, text string) (bool, error) {
+	const op = "storage.memory.translations.SaveTranslation"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return false, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	if item.translations == nil {
+		item.translations = make(map[string]string)
+	}
+
+	_, exists := item.translations[language]
+	item.translations[language] = lyrics.Normalize(text)
+
+	return !exists, nil
+}
+
+func (s *MStorage) GetTranslation(ctx context.Context, id int64, language string) (models.Translation, error) {
+	const op = "storage.memory.translations.GetTranslation"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	text, ok := item.translations[language]
+	if !ok {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoTranslation))
+	}
+
+	return models.Translation{SongID: id, Language: language, Lyrics: text}, nil
+}
+
+// GetTextTranslation returns the translation to song.Lang of the song found by band and title.
+func (s *MStorage) GetTextTranslation(ctx context.Context, song models.SongLyrics) (models.Translation, error) {
+	const op = "storage.memory.translations.GetTextTranslation"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item := s.find(song.BandName, song.SongTitle)
+	if item == nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound))
+	}
+
+	text, ok := item.translations[song.Lang]
+	if !ok {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, errs.ErrNoTranslation))
+	}
+
+	return models.Translation{SongID: item.id, Language: song.Lang, Lyrics: text}, nil
+}
+
+// GetTranslations returns every translation of the song, ordered by language.
+func (s *MStorage) GetTranslations(ctx context.Context, id int64) ([]models.Translation, error) {
+	const op = "storage.memory.translations.GetTranslations"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	translations := make([]models.Translation, 0, len(item.translations))
+	for language, text := range item.translations {
+		translations = append(translations, models.Translation{SongID: id, Language: language, Lyrics: text})
+	}
+
+	slices.SortFunc(translations, func(a, b models.Translation) int {
+		return strings.Compare(a.Language, b.Language)
+	})
+
+	return translations, nil
+}
+
+func (s *MStorage) DeleteTranslation(ctx context.Context, id int64, language string) error {
+	const op = "storage.memory.translations.DeleteTranslation"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.songs[id]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	if _, ok := item.translations[language]; !ok {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoTranslation))
+	}
+
+	delete(item.translations, language)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+)
+
+// SQLSTATE codes of the integrity constraint violations.
+const (
+	notNullViolation    = "23502"
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// mapError translates driver errors into the domain taxonomy. notFound is returned in place of
+// pgx.ErrNoRows, so every caller can describe the song it was looking for.
+func mapError(err error, notFound error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notFound
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case pgErr.Code == uniqueViolation:
+		return &errs.ConstraintError{Constraint: pgErr.ConstraintName, Err: errs.ErrSongExists, Cause: err}
+	case pgErr.Code == foreignKeyViolation:
+		return &errs.ConstraintError{Constraint: pgErr.ConstraintName, Err: errs.ErrSongNotFound, Cause: err}
+	case pgErr.Code == notNullViolation, pgErr.Code == checkViolation:
+		return &errs.ConstraintError{Constraint: pgErr.ConstraintName, Err: errs.ErrInvalidSong, Cause: err}
+	case strings.HasPrefix(pgErr.Code, "22"):
+		// Class 22 is a data exception, such as a release date TO_DATE cannot parse.
+		return &errs.ConstraintError{Err: errs.ErrInvalidSong, Cause: err}
+	default:
+		return err
+	}
+}
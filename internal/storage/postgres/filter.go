@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/match"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+)
+
+type queryArgs struct {
+	values []any
+}
+
+func (q *queryArgs) add(value any) string {
+	q.values = append(q.values, value)
+
+	return fmt.Sprintf("$%d", len(q.values))
+}
+
+func songConditions(filter models.SongFilter, args *queryArgs) ([]string, error) {
+	var conditions []string
+
+	// A fuzzy match relies on pg_trgm.similarity_threshold, see setSimilarity.
+	switch {
+	case filter.BandName != "" && filter.Fuzzy:
+		conditions = append(conditions, `band % `+args.add(filter.BandName))
+	case filter.BandName != "":
+		conditions = append(conditions, matchCondition("band", filter.BandMatch, models.MatchExact, filter.BandName, args))
+	}
+
+	switch {
+	case filter.SongTitle != "" && filter.Fuzzy:
+		conditions = append(conditions, `song % `+args.add(filter.SongTitle))
+	case filter.SongTitle != "":
+		conditions = append(conditions, matchCondition("song", filter.TitleMatch, models.MatchExact, filter.SongTitle, args))
+	}
+
+	if filter.Lyrics != "" {
+		conditions = append(conditions, matchCondition("lyrics", filter.LyricsMatch, models.MatchContains, filter.Lyrics, args))
+	}
+
+	release, err := releasedate.FilterRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if release.From != nil {
+		conditions = append(conditions, `release >= `+args.add(*release.From))
+	}
+
+	if release.To != nil {
+		conditions = append(conditions, `release <= `+args.add(*release.To))
+	}
+
+	if filter.HasLink != nil {
+		if *filter.HasLink {
+			conditions = append(conditions, `COALESCE(link, '') <> ''`)
+		} else {
+			conditions = append(conditions, `COALESCE(link, '') = ''`)
+		}
+	}
+
+	return conditions, nil
+}
+
+func matchCondition(column string, mode, def models.MatchMode, pattern string, args *queryArgs) string {
+	kind, insensitive := match.Resolve(mode, def)
+
+	switch {
+	case kind == models.MatchExact && insensitive:
+		return fmt.Sprintf(`LOWER(%s) = LOWER(%s)`, column, args.add(pattern))
+	case kind == models.MatchExact:
+		return fmt.Sprintf(`%s = %s`, column, args.add(pattern))
+	}
+
+	operator := "LIKE"
+	if insensitive {
+		operator = "ILIKE"
+	}
+
+	pattern = match.EscapeLike(pattern) + "%"
+	if kind == models.MatchContains {
+		pattern = "%" + pattern
+	}
+
+	return fmt.Sprintf(`%s %s %s`, column, operator, args.add(pattern))
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// ClosestNames finds the existing band and title most similar to the given ones, for a "did you
+// mean" suggestion. A name is left empty when it was not asked for or nothing reaches threshold.
+func (s *PStorage) ClosestNames(ctx context.Context, band, title string, threshold float64) (names models.DidYouMean, err error) {
+	const op = "storage.postgres.fuzzy.ClosestNames"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return models.DidYouMean{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		commitErr := tx.Commit(ctx)
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	err = setSimilarity(ctx, tx, threshold)
+	if err != nil {
+		return models.DidYouMean{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, name := range []struct {
+		column string
+		value  string
+		to     *string
+	}{
+		{"band", band, &names.BandName},
+		{"song", title, &names.SongTitle},
+	} {
+		if name.value == "" {
+			continue
+		}
+
+		*name.to, err = closestName(ctx, tx, name.column, name.value)
+		if err != nil {
+			return models.DidYouMean{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return names, nil
+}
+
+func closestName(ctx context.Context, tx pgx.Tx, column, value string) (string, error) {
+	var closest string
+
+	err := tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT %[1]s
+		FROM songs
+		WHERE %[1]s %% $1
+		ORDER BY similarity(%[1]s, $1) DESC, %[1]s
+		LIMIT 1;
+	`, column), value).Scan(&closest)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return closest, err
+}
+
+// setSimilarity sets the threshold of the pg_trgm % operator for the rest of the transaction.
+// The operator, unlike a comparison of similarity(), can use the trigram indexes.
+func setSimilarity(ctx context.Context, tx pgx.Tx, threshold float64) error {
+	_, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true);`, strconv.FormatFloat(threshold, 'f', -1, 64))
+
+	return err
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+func (s *PStorage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (models.EnrichmentJob, error) {
+	const op = "storage.postgres.jobs.ClaimEnrichmentJob"
+
+	row := s.pool.QueryRow(ctx, `
+		UPDATE enrichment_jobs j
+		SET state = 'running', attempts = j.attempts + 1, run_at = NOW() + make_interval(secs => $1), updated = NOW()
+		FROM songs s
+		WHERE j.id = (
+			SELECT id
+			FROM enrichment_jobs
+			WHERE state IN ('pending', 'running') AND run_at <= NOW()
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		) AND s.id = j.song_id
+		RETURNING j.id, j.song_id, s.band, s.song, j.attempts;
+	`, lease.Seconds())
+
+	var job models.EnrichmentJob
+
+	err := row.Scan(&job.ID, &job.SongID, &job.BandName, &job.SongTitle, &job.Attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.EnrichmentJob{}, errs.ErrNoJobs
+		}
+
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return job, nil
+}
+
+// CompleteEnrichmentJob marks the job done and fills in the details the song still lacks. Details
+// the client already gave are kept, and blank upstream values leave a field empty.
+func (s *PStorage) CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) (err error) {
+	const op = "storage.postgres.jobs.CompleteEnrichmentJob"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		commitErr := tx.Commit(ctx)
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	var songID int64
+
+	err = tx.QueryRow(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'done', last_error = NULL, updated = NOW()
+		WHERE id = $1
+		RETURNING song_id;
+	`, jobID).Scan(&songID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ErrSongNotFound))
+	}
+
+	var current *string
+
+	err = tx.QueryRow(ctx, `
+		SELECT lyrics
+		FROM songs
+		WHERE id = $1
+		FOR UPDATE;
+	`, songID).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ErrSongNotFound))
+	}
+
+	text := lyrics.Normalize(details.Lyrics)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE songs
+		SET release = COALESCE(release, TO_DATE(NULLIF($2, ''), 'DD.MM.YYYY')), lyrics = COALESCE(lyrics, NULLIF($3, '')),
+			link = COALESCE(link, NULLIF($4, '')), status = 'enriched', updated = NOW()
+		WHERE id = $1;
+	`, songID, details.ReleaseDate, text, details.Link)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, nil))
+	}
+
+	if current != nil || text == "" {
+		return nil
+	}
+
+	err = replaceVerses(ctx, tx, songID, text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PStorage) RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) error {
+	const op = "storage.postgres.jobs.RetryEnrichmentJob"
+
+	_, err := s.pool.Exec(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'pending', run_at = NOW() + make_interval(secs => $2), last_error = $3, updated = NOW()
+		WHERE id = $1;
+	`, jobID, delay.Seconds(), reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FailEnrichmentJob moves the job to the dead letter state. Its song is marked failed unless the
+// client already gave every detail, in which case nothing is missing and it counts as enriched.
+func (s *PStorage) FailEnrichmentJob(ctx context.Context, jobID int64, reason string) error {
+	const op = "storage.postgres.jobs.FailEnrichmentJob"
+
+	_, err := s.pool.Exec(ctx, `
+		WITH dead AS (
+			UPDATE enrichment_jobs
+			SET state = 'dead', last_error = $2, updated = NOW()
+			WHERE id = $1
+			RETURNING song_id
+		)
+		UPDATE songs
+		SET status = CASE WHEN release IS NULL OR lyrics IS NULL OR link IS NULL THEN 'failed' ELSE 'enriched' END,
+			updated = NOW()
+		WHERE id IN (SELECT song_id FROM dead);
+	`, jobID, reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *PStorage) RequeueFailedJobs(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.jobs.RequeueFailedJobs"
+
+	tag, err := s.pool.Exec(ctx, `
+		WITH requeued AS (
+			UPDATE enrichment_jobs
+			SET state = 'pending', attempts = 0, run_at = NOW(), last_error = NULL, updated = NOW()
+			WHERE state = 'dead'
+			RETURNING song_id
+		)
+		UPDATE songs
+		SET status = 'pending', updated = NOW()
+		WHERE id IN (SELECT song_id FROM requeued);
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -6,23 +6,60 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/jackc/pgconn"
-	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
 	"github.com/stepan41k/Testovoe/internal/domain/models"
-	"github.com/stepan41k/Testovoe/internal/storage"
-)
-
-const (
-	sizeOfVerse = 1
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
 )
 
 
-func (s *PStorage) GetSongs(ctx context.Context, song models.SongFilter) ([]models.Song, error) {
+func (s *PStorage) GetSongs(ctx context.Context, song models.SongFilter) (models.SongList, error) {
 	const op = "storage.postgres.music.GetSongs"
 
+	keys, err := sorting.ForSearch(song.Sort, song.Ranked())
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, err := cursor.Decode(song.Cursor, keys)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	args := &queryArgs{}
+
+	source, err := songSource(song, args)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `SELECT id, band, song, TO_CHAR(release, 'DD.MM.YYYY'), lyrics, link, status, language` + searchColumns(song) + sortColumns(keys) + ` FROM ` + source
+
+	conditions, err := songConditions(song, args)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	where, filterArgs := strings.Join(conditions, " AND "), len(args.values)
+
+	limit, offset := song.PageSize, (song.Page-1)*song.PageSize
+	if after != nil {
+		conditions = append(conditions, keysetCondition(keys, after, args))
+		offset = 0
+	}
+
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	query += orderBy(keys, after != nil && after.Backward)
+	query += fmt.Sprintf(` LIMIT %s OFFSET %s;`, args.add(limit+1), args.add(offset))
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	defer func() {
@@ -36,102 +73,103 @@ func (s *PStorage) GetSongs(ctx context.Context, song models.SongFilter) ([]mode
 			err = fmt.Errorf("%s: %w", op, commitErr)
 		}
 	}()
-	
-	arguments, values, ind := []string{}, []any{}, 1
-	query := `SELECT band, song, TO_CHAR(release, 'DD.MM.YYYY'), lyrics, link FROM songs`
-
-	if song.BandName != "" || song.SongTitle != "" || song.ReleaseDate != "" {
-		query += ` WHERE `
-		switch {
-		case song.SongTitle != "":
-			arguments = append(arguments, fmt.Sprintf(`song LIKE $%d`, ind))
-			values = append(values, song.SongTitle)
-			ind++
-		case song.BandName != "":
-			arguments = append(arguments, fmt.Sprintf(`band LIKE $%d`, ind))
-			values = append(values, song.BandName)
-			ind++
-		case song.ReleaseDate != "":
-			if song.Later {
-				arguments = append(arguments, fmt.Sprintf(`release > TO_DATE($%d, 'DD.MM.YYYY')`, ind))
-			} else if !song.Later {
-				arguments = append(arguments, fmt.Sprintf(`release <= TO_DATE($%d, 'DD.MM.YYYY')`, ind))
-			} else {
-				return nil, fmt.Errorf("%s: %w", op, storage.ErrNoChanges)
-			}
-			values = append(values, song.ReleaseDate)
-			ind++
-		}
-		query += strings.Join(arguments, ",")
-	} 
 
-	query += fmt.Sprintf(` LIMIT $%d OFFSET $%d;`, ind, ind+1)
-	values = append(values, song.PageSize, (song.Page-1)*song.PageSize)
-
-
-	rows, err := tx.Query(ctx, query, values...)
+	if song.Fuzzy {
+		err = setSimilarity(ctx, tx, song.Threshold())
+		if err != nil {
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
 
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	var total *int64
+	if song.Count {
+		total, err = s.countSongs(ctx, tx, source, where, args.values[:filterArgs])
+		if err != nil {
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
 		}
+	}
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+	rows, err := tx.Query(ctx, query, args.values...)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	defer rows.Close()
 
-	var songs []models.Song
+	type row struct {
+		song   models.Song
+		id     int64
+		rank   float32
+		values []string
+	}
+
+	var items []row
 	for rows.Next() {
-		var item models.Song
-		err = rows.Scan(&item)
+		var item row
+		var release, lyrics, link, language *string
+
+		item.values = make([]string, len(keys))
+		dest := []any{&item.id, &item.song.BandName, &item.song.SongTitle, &release, &lyrics, &link, &item.song.Status, &language, &item.rank, &item.song.Snippet}
+		for i := range item.values {
+			dest = append(dest, &item.values[i])
+		}
+
+		err = rows.Scan(dest...)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
 		}
-		songs = append(songs, item)
+
+		item.song.ReleaseDate, item.song.Lyrics, item.song.Link, item.song.Language = deref(release), deref(lyrics), deref(link), deref(language)
+		items = append(items, item)
 	}
-	
-	return songs, err
-}
 
+	if err = rows.Err(); err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-func (s *PStorage) GetTextSong(ctx context.Context, song models.SongLyrics) (string, error) {
-	const op = "storage.postgres.music.GetTextSong"
+	page, next, prev := cursor.Paginate(items, limit, after, offset, keys, func(item row) ([]string, int64) {
+		return item.values, item.id
+	})
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+	songs := make([]models.Song, 0, len(page))
+	for _, item := range page {
+		item.song.ID, item.song.Rank = item.id, float64(item.rank)
+		songs = append(songs, item.song)
 	}
 
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback(ctx)
-			return
-		}
+	return models.SongList{Songs: songs, NextCursor: next, PrevCursor: prev, Total: total}, nil
+}
 
-		commitErr := tx.Commit(ctx)
-		if commitErr != nil {
-			err = fmt.Errorf("%s: %w", op, commitErr)
-		}
-	}()
 
-	row := tx.QueryRow(ctx, `
-		WITH split_text AS (
-                SELECT song, band, unnest(regexp_split_to_array(lyrics, E'\n\n'))
-        AS verse
-                FROM songs
-        )
-        SELECT verse
-        FROM split_text
-		WHERE song = $1 AND band = $2
-        LIMIT $3 OFFSET $4;
-	`, song.SongTitle, song.BandName, sizeOfVerse, song.Verse-1)
+// countSongs counts every song of source matching the filter conditions, ignoring paging.
+func (s *PStorage) countSongs(ctx context.Context, tx pgx.Tx, source, where string, args []any) (*int64, error) {
+	query := `SELECT COUNT(*) FROM ` + source
+	if where != "" {
+		query += ` WHERE ` + where
+	}
+
+	var total int64
 
-	var verse string
+	if err := tx.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
+
+
+func (s *PStorage) GetTextSong(ctx context.Context, song models.SongLyrics) (models.Verse, error) {
+	const op = "storage.postgres.music.GetTextSong"
 
-	err = row.Scan(&verse)
+	verse, err := scanVerse(s.pool.QueryRow(ctx, `
+		SELECT song_verses.position, song_verses.kind, song_verses.text,
+			(SELECT COUNT(*) FROM song_verses AS counted WHERE counted.song_id = songs.id), songs.language
+		FROM songs
+		JOIN song_verses ON song_verses.song_id = songs.id
+		WHERE songs.song = $1 AND songs.band = $2 AND song_verses.position = $3;
+	`, song.SongTitle, song.BandName, song.Verse))
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.Verse{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)))
 	}
 
 	return verse, nil
@@ -166,66 +204,27 @@ func (s *PStorage) DeleteSong(ctx context.Context, song models.Song) (id int64,
 
 	err = row.Scan(&id)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, mapError(err, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)))
 	}
 
 	return id, nil
 }
 
 
-func (s *PStorage) UpdateSong(ctx context.Context, song models.Song) (id int64, err error) {
+// UpdateSong applies every non-empty field of the legacy update body to the song found by band and title.
+func (s *PStorage) UpdateSong(ctx context.Context, song models.Song) (int64, error) {
 	const op = "storage.postgres.music.UpdateSong"
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
-	}
+	notFound := errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)
 
-	defer func ()  {
-		if err != nil {
-			_ = tx.Rollback(ctx)
-			return
-		}
-
-		commitErr := tx.Commit(ctx)
-		if commitErr != nil {
-			err = fmt.Errorf("%s: %w", op, commitErr)
-		}
-	}()
-
-	arguments, values, ind := []string{}, []any{}, 1
-	query := `UPDATE songs SET updated = NOW(), `
-
-	if song.BandName != "" || song.SongTitle != "" || song.ReleaseDate != "" {
-		switch {
-		case song.Link != "":
-			arguments = append(arguments, fmt.Sprintf(`link = $%d`, ind))
-			values = append(values, song.Link)
-			ind++
-		case song.Lyrics != "":
-			arguments = append(arguments, fmt.Sprintf(`lyrics = $%d`, ind))
-			values = append(values, song.Lyrics)
-			ind++
-		case song.ReleaseDate != "":
-			arguments = append(arguments, fmt.Sprintf(`release = TO_DATE($%d, 'DD.MM.YYYY')`, ind))
-			values = append(values, song.ReleaseDate)
-			ind++
-		}
-	} else {
-		return 0, fmt.Errorf("%s: %w", op, storage.ErrNoChanges)
-	}
-
-	query += strings.Join(arguments, ",")
-	query += fmt.Sprintf(` WHERE song = $%d AND band = $%d RETURNING id;`, ind, ind+1)
-	values = append(values, song.SongTitle, song.BandName)
-
-
-	row := tx.QueryRow(ctx, query, values...)
-	err = row.Scan(&id)
+	updated, err := s.patchSong(ctx, models.LegacyPatch(song), notFound, func(args *queryArgs) string {
+		return fmt.Sprintf(`song = %s AND band = %s`, args.add(song.SongTitle), args.add(song.BandName))
+	})
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	return id, nil
+
+	return updated.ID, nil
 }
 
 
@@ -249,20 +248,44 @@ func (s *PStorage) AddNewSong(ctx context.Context, song models.Song) (id int64,
 		}
 	}()
 
+	text := lyrics.Normalize(song.Lyrics)
+
 	row := tx.QueryRow(ctx, `
-		INSERT INTO songs (band, song, updated)
-		VALUES ($1, $2, NOW())
+		INSERT INTO songs (band, song, release, lyrics, link, language, updated)
+		VALUES ($1, $2, TO_DATE(NULLIF($3, ''), 'DD.MM.YYYY'), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NOW())
 		RETURNING id;
-	`, song.BandName, song.SongTitle)
+	`, song.BandName, song.SongTitle, song.ReleaseDate, text, song.Link, song.Language)
 
 	err = row.Scan(&id)
 	if err != nil {
-		pgErr := err.(*pgconn.PgError)
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return 0, fmt.Errorf("%s: %w", op, storage.ErrSongExists)
+		var constraintErr *errs.ConstraintError
+		if err = mapError(err, nil); errors.As(err, &constraintErr) {
+			err = errs.ByName(song.BandName, song.SongTitle, err)
 		}
 
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+
+	err = replaceVerses(ctx, tx, id, text)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO enrichment_jobs (song_id)
+		VALUES ($1);
+	`, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return id, nil
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
 }
\ No newline at end of file
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// nameColumns maps a name kind to its column. The column is spliced into the SQL, so only these are accepted.
+var nameColumns = map[models.NameKind]string{
+	models.NameBand: "band",
+	models.NameSong: "song",
+}
+
+// Names lists every distinct band or song title with the number of songs carrying it.
+func (s *PStorage) Names(ctx context.Context, kind models.NameKind) ([]models.Name, error) {
+	const op = "storage.postgres.names.Names"
+
+	column, ok := nameColumns[kind]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown name kind %q", op, kind)
+	}
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM songs
+		GROUP BY %[1]s
+		ORDER BY %[1]s;
+	`, column))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	var names []models.Name
+	for rows.Next() {
+		var name models.Name
+
+		if err := rows.Scan(&name.Value, &name.Songs); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return names, nil
+}
@@ -13,6 +13,7 @@ type PStorage struct {
 	mu *sync.Mutex
 }
 
+// New connects to a database the migrations were already applied to.
 func New(ctx context.Context, storagePath string) (*PStorage, error) {
 	const op = "storage.postgres.New"
 
@@ -21,6 +22,11 @@ func New(ctx context.Context, storagePath string) (*PStorage, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	if err := backfillVerses(ctx, pool); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return &PStorage{
 		pool: pool,
 		mu: &sync.Mutex{},
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// searchConfigs maps a search language to its text search configuration and the generated
+// tsvector column indexed with it. The configuration is spliced into the SQL, so only these are accepted.
+var searchConfigs = map[string]string{
+	"simple":  "lyrics_tsv_simple",
+	"english": "lyrics_tsv_english",
+	"russian": "lyrics_tsv_russian",
+}
+
+var searchFunctions = map[models.SearchMode]string{
+	models.SearchWebsearch: "websearch_to_tsquery",
+	models.SearchPhrase:    "phraseto_tsquery",
+	models.SearchPlain:     "plainto_tsquery",
+}
+
+// songSource returns the relation songs are listed from. A ranked listing adds the rank of every
+// song, so it can be sorted on and paged through like any other column: the ts_rank of a lyrics
+// search, which also narrows the songs to the matching ones, plus the similarity of fuzzily matched
+// band and title.
+func songSource(filter models.SongFilter, args *queryArgs) (string, error) {
+	if !filter.Ranked() {
+		return `songs`, nil
+	}
+
+	var scores []string
+	from, query, where := `songs`, `NULL::tsquery`, ``
+
+	if filter.Search != "" {
+		config, column, function, err := searchParams(filter)
+		if err != nil {
+			return "", err
+		}
+
+		from += fmt.Sprintf(`, %s('%s', %s) AS search`, function, config, args.add(filter.Search))
+		query, where = `search`, fmt.Sprintf(` WHERE %s @@ search`, column)
+		scores = append(scores, fmt.Sprintf(`ts_rank(%s, search)`, column))
+	}
+
+	if filter.Fuzzy && filter.BandName != "" {
+		scores = append(scores, `similarity(band, `+args.add(filter.BandName)+`)`)
+	}
+
+	if filter.Fuzzy && filter.SongTitle != "" {
+		scores = append(scores, `similarity(song, `+args.add(filter.SongTitle)+`)`)
+	}
+
+	return fmt.Sprintf(`(
+		SELECT songs.*, %s AS rank, %s AS query
+		FROM %s%s
+	) AS songs`, strings.Join(scores, ` + `), query, from, where), nil
+}
+
+// searchColumns selects the rank and highlighted snippet of a listed song, zero and empty when
+// there is nothing to rank or highlight.
+func searchColumns(filter models.SongFilter) string {
+	if !filter.Ranked() {
+		return `, 0::real, ''`
+	}
+
+	if filter.Search == "" {
+		return `, rank, ''`
+	}
+
+	config, _, _, _ := searchParams(filter)
+
+	return fmt.Sprintf(`, rank, ts_headline('%s', COALESCE(lyrics, ''), query)`, config)
+}
+
+func searchParams(filter models.SongFilter) (config, column, function string, err error) {
+	config = filter.SearchLanguage
+	if config == "" {
+		config = "simple"
+	}
+
+	column, ok := searchConfigs[config]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown search language %q", config)
+	}
+
+	mode := filter.SearchMode
+	if mode == "" {
+		mode = models.SearchWebsearch
+	}
+
+	function, ok = searchFunctions[mode]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown search mode %q", mode)
+	}
+
+	return config, column, function, nil
+}
@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+const songColumns = `id, band, song, TO_CHAR(release, 'DD.MM.YYYY'), lyrics, link, status, language`
+
+func (s *PStorage) GetSong(ctx context.Context, id int64) (models.Song, error) {
+	const op = "storage.postgres.songs.GetSong"
+
+	row := s.pool.QueryRow(ctx, `
+		SELECT `+songColumns+`
+		FROM songs
+		WHERE id = $1;
+	`, id)
+
+	song, err := scanSong(row)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return song, nil
+}
+
+// ReplaceSong overwrites every editable field of the song. Empty fields are stored as NULL.
+func (s *PStorage) ReplaceSong(ctx context.Context, id int64, song models.Song) (replaced models.Song, err error) {
+	const op = "storage.postgres.songs.ReplaceSong"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		commitErr := tx.Commit(ctx)
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	text := lyrics.Normalize(song.Lyrics)
+
+	row := tx.QueryRow(ctx, `
+		UPDATE songs
+		SET band = $1, song = $2, release = TO_DATE(NULLIF($3, ''), 'DD.MM.YYYY'), lyrics = NULLIF($4, ''), link = NULLIF($5, ''), language = NULLIF($6, ''), updated = NOW()
+		WHERE id = $7
+		RETURNING `+songColumns+`;
+	`, song.BandName, song.SongTitle, song.ReleaseDate, text, song.Link, song.Language, id)
+
+	replaced, err = scanSong(row)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	err = replaceVerses(ctx, tx, id, text)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return replaced, nil
+}
+
+// PatchSong changes the fields set in the patch in one statement and returns the updated song.
+func (s *PStorage) PatchSong(ctx context.Context, id int64, patch models.SongPatch) (models.Song, error) {
+	const op = "storage.postgres.songs.PatchSong"
+
+	song, err := s.patchSong(ctx, patch, errs.ByID(id, errs.ErrSongNotFound), func(args *queryArgs) string {
+		return `id = ` + args.add(id)
+	})
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return song, nil
+}
+
+// patchSong updates the song matched by where. notFound is returned when no song matches.
+func (s *PStorage) patchSong(ctx context.Context, patch models.SongPatch, notFound error, where func(args *queryArgs) string) (song models.Song, err error) {
+	if patch.Empty() {
+		return models.Song{}, errs.ErrNoChanges
+	}
+
+	// Lyrics are stored normalized, and lyrics that normalize to nothing are cleared.
+	if patch.Lyrics.Set && !patch.Lyrics.Null {
+		patch.Lyrics.Value = lyrics.Normalize(patch.Lyrics.Value)
+		patch.Lyrics.Null = patch.Lyrics.Value == ""
+	}
+
+	args := &queryArgs{}
+	assignments := []string{`updated = NOW()`}
+
+	for _, field := range []struct {
+		column string
+		value  models.PatchField
+		format func(placeholder string) string
+	}{
+		{`band`, patch.BandName, nil},
+		{`song`, patch.SongTitle, nil},
+		{`release`, patch.ReleaseDate, func(placeholder string) string { return `TO_DATE(` + placeholder + `, 'DD.MM.YYYY')` }},
+		{`lyrics`, patch.Lyrics, nil},
+		{`link`, patch.Link, nil},
+		{`language`, patch.Language, nil},
+	} {
+		switch {
+		case !field.value.Set:
+			continue
+		case field.value.Null:
+			assignments = append(assignments, field.column+` = NULL`)
+		case field.format != nil:
+			assignments = append(assignments, field.column+` = `+field.format(args.add(field.value.Value)))
+		default:
+			assignments = append(assignments, field.column+` = `+args.add(field.value.Value))
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+	}()
+
+	row := tx.QueryRow(ctx, `
+		UPDATE songs
+		SET `+strings.Join(assignments, ", ")+`
+		WHERE `+where(args)+`
+		RETURNING `+songColumns+`;
+	`, args.values...)
+
+	song, err = scanSong(row)
+	if err != nil {
+		return models.Song{}, mapError(err, notFound)
+	}
+
+	if patch.Lyrics.Set {
+		err = replaceVerses(ctx, tx, song.ID, song.Lyrics)
+		if err != nil {
+			return models.Song{}, err
+		}
+	}
+
+	return song, nil
+}
+
+func (s *PStorage) DeleteSongByID(ctx context.Context, id int64) error {
+	const op = "storage.postgres.songs.DeleteSongByID"
+
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM songs
+		WHERE id = $1;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return nil
+}
+
+func scanSong(row pgx.Row) (models.Song, error) {
+	var song models.Song
+	var release, lyrics, link, language *string
+
+	err := row.Scan(&song.ID, &song.BandName, &song.SongTitle, &release, &lyrics, &link, &song.Status, &language)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	song.ReleaseDate, song.Lyrics, song.Link, song.Language = deref(release), deref(lyrics), deref(link), deref(language)
+
+	return song, nil
+}
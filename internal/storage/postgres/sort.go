@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+// sortColumn maps a whitelisted sort field to its SQL expression. Nullable columns are coalesced
+// to a sentinel so that NULLs come last in both directions.
+func sortColumn(key sorting.Key) string {
+	switch key.Field {
+	case sorting.FieldBand:
+		return `band`
+	case sorting.FieldTitle:
+		return `song`
+	case sorting.FieldRelease:
+		if key.Desc {
+			return `COALESCE(release, DATE '0001-01-01')`
+		}
+		return `COALESCE(release, DATE '9999-12-31')`
+	case sorting.FieldUpdated:
+		if key.Desc {
+			return `COALESCE(updated, TIMESTAMP '0001-01-01')`
+		}
+		return `COALESCE(updated, TIMESTAMP '9999-12-31')`
+	case sorting.FieldRank:
+		return `rank`
+	default:
+		return `id`
+	}
+}
+
+// sortValue casts a cursor value back to the type of the sort column.
+func sortValue(key sorting.Key, placeholder string) string {
+	switch key.Field {
+	case sorting.FieldRelease:
+		return placeholder + `::date`
+	case sorting.FieldUpdated:
+		return placeholder + `::timestamp`
+	case sorting.FieldRank:
+		return placeholder + `::real`
+	default:
+		return placeholder
+	}
+}
+
+// sortColumns selects the sort expressions as text, so a cursor can be built from any row.
+func sortColumns(keys []sorting.Key) string {
+	var columns strings.Builder
+
+	for _, key := range keys {
+		columns.WriteString(`, ` + sortColumn(key) + `::text`)
+	}
+
+	return columns.String()
+}
+
+func orderBy(keys []sorting.Key, backward bool) string {
+	parts := make([]string, 0, len(keys)+1)
+
+	for _, key := range keys {
+		parts = append(parts, sortColumn(key)+direction(key.Desc != backward))
+	}
+
+	parts = append(parts, `id`+direction(backward))
+
+	return ` ORDER BY ` + strings.Join(parts, ", ")
+}
+
+// keysetCondition matches the rows that come after the cursor in sort order, or before it for a backward cursor.
+func keysetCondition(keys []sorting.Key, c *cursor.Cursor, args *queryArgs) string {
+	disjuncts := make([]string, 0, len(keys)+1)
+
+	for i := 0; i <= len(keys); i++ {
+		parts := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf(`%s = %s`, sortColumn(keys[j]), sortValue(keys[j], args.add(c.Values[j]))))
+		}
+
+		if i < len(keys) {
+			parts = append(parts, fmt.Sprintf(`%s %s %s`, sortColumn(keys[i]), comparison(keys[i].Desc != c.Backward), sortValue(keys[i], args.add(c.Values[i]))))
+		} else {
+			parts = append(parts, fmt.Sprintf(`id %s %s`, comparison(c.Backward), args.add(c.ID)))
+		}
+
+		disjuncts = append(disjuncts, `(`+strings.Join(parts, ` AND `)+`)`)
+	}
+
+	return `(` + strings.Join(disjuncts, ` OR `) + `)`
+}
+
+func direction(desc bool) string {
+	if desc {
+		return ` DESC`
+	}
+
+	return ` ASC`
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return `<`
+	}
+
+	return `>`
+}
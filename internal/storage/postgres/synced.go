@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// ReplaceSyncedLyrics stores the synced lines of the song in place of the previous ones. Lines are
+// numbered in the order given.
+func (s *PStorage) ReplaceSyncedLyrics(ctx context.Context, id int64, lines []models.SyncedLine) (err error) {
+	const op = "storage.postgres.synced.ReplaceSyncedLyrics"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+
+		commitErr := tx.Commit(ctx)
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	err = tx.QueryRow(ctx, `
+		UPDATE songs
+		SET updated = NOW()
+		WHERE id = $1
+		RETURNING id;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM song_synced_lines
+		WHERE song_id = $1;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, line := range lines {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO song_synced_lines (song_id, position, time_ms, text)
+			VALUES ($1, $2, $3, $4);
+		`, id, i+1, line.TimeMs, line.Text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+		}
+	}
+
+	return nil
+}
+
+// GetSyncedLyrics returns the synced lines of the song in order.
+func (s *PStorage) GetSyncedLyrics(ctx context.Context, id int64) ([]models.SyncedLine, error) {
+	const op = "storage.postgres.synced.GetSyncedLyrics"
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT song_synced_lines.position, song_synced_lines.time_ms, song_synced_lines.text
+		FROM songs
+		LEFT JOIN song_synced_lines ON song_synced_lines.song_id = songs.id
+		WHERE songs.id = $1
+		ORDER BY song_synced_lines.position;
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	found := false
+	var lines []models.SyncedLine
+
+	for rows.Next() {
+		var position *int
+		var timeMs *int64
+		var text *string
+
+		if err = rows.Scan(&position, &timeMs, &text); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		found = true
+		if position != nil {
+			lines = append(lines, models.SyncedLine{Number: *position, TimeMs: *timeMs, Text: deref(text)})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch {
+	case !found:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	case len(lines) == 0:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+	}
+
+	return lines, nil
+}
+
+// DeleteSyncedLyrics removes the synced lines of the song, leaving its plain lyrics alone.
+func (s *PStorage) DeleteSyncedLyrics(ctx context.Context, id int64) error {
+	const op = "storage.postgres.synced.DeleteSyncedLyrics"
+
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM song_synced_lines
+		WHERE song_id = $1;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		SELECT id
+		FROM songs
+		WHERE id = $1;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+// SaveTranslation adds the translation of the song's lyrics to the language, or replaces the one
+// it has. created reports whether the song had no translation to the language before: xmax is zero
+// only on a row the upsert inserted, so concurrent saves of a new language agree on which created it.
+func (s *PStorage) SaveTranslation(ctx context.Context, id int64, language, text string) (bool, error) {
+	const op = "storage.postgres.translations.SaveTranslation"
+
+	var created bool
+
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO song_lyrics_translations (song_id, language, lyrics, updated)
+		SELECT id, $2, $3, NOW()
+		FROM songs
+		WHERE id = $1
+		ON CONFLICT (song_id, language) DO UPDATE
+		SET lyrics = EXCLUDED.lyrics, updated = EXCLUDED.updated
+		RETURNING (xmax = 0);
+	`, id, language, lyrics.Normalize(text)).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return created, nil
+}
+
+func (s *PStorage) GetTranslation(ctx context.Context, id int64, language string) (models.Translation, error) {
+	const op = "storage.postgres.translations.GetTranslation"
+
+	translation, err := s.translation(ctx, `songs.id = $2`, language, id)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByID(id, err))
+	}
+
+	return translation, nil
+}
+
+// GetTextTranslation returns the translation to song.Lang of the song found by band and title.
+func (s *PStorage) GetTextTranslation(ctx context.Context, song models.SongLyrics) (models.Translation, error) {
+	const op = "storage.postgres.translations.GetTextTranslation"
+
+	translation, err := s.translation(ctx, `songs.song = $2 AND songs.band = $3`, song.Lang, song.SongTitle, song.BandName)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, err))
+	}
+
+	return translation, nil
+}
+
+// translation returns the translation to language of the song matched by where. It fails with
+// errs.ErrSongNotFound or errs.ErrNoTranslation, for the caller to tie to the song. language is $1,
+// so the placeholders of where start at $2.
+func (s *PStorage) translation(ctx context.Context, where, language string, args ...any) (models.Translation, error) {
+	translation := models.Translation{Language: language}
+	var text *string
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT songs.id, song_lyrics_translations.lyrics
+		FROM songs
+		LEFT JOIN song_lyrics_translations
+			ON song_lyrics_translations.song_id = songs.id AND song_lyrics_translations.language = $1
+		WHERE `+where+`;
+	`, append([]any{language}, args...)...).Scan(&translation.SongID, &text)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return models.Translation{}, errs.ErrSongNotFound
+	case err != nil:
+		return models.Translation{}, err
+	case text == nil:
+		return models.Translation{}, errs.ErrNoTranslation
+	}
+
+	translation.Lyrics = *text
+
+	return translation, nil
+}
+
+// GetTranslations returns every translation of the song, ordered by language.
+func (s *PStorage) GetTranslations(ctx context.Context, id int64) ([]models.Translation, error) {
+	const op = "storage.postgres.translations.GetTranslations"
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT song_lyrics_translations.language, song_lyrics_translations.lyrics
+		FROM songs
+		LEFT JOIN song_lyrics_translations ON song_lyrics_translations.song_id = songs.id
+		WHERE songs.id = $1
+		ORDER BY song_lyrics_translations.language;
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	found := false
+	translations := []models.Translation{}
+
+	for rows.Next() {
+		var language, text *string
+
+		if err = rows.Scan(&language, &text); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		found = true
+		if language != nil {
+			translations = append(translations, models.Translation{SongID: id, Language: *language, Lyrics: deref(text)})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return translations, nil
+}
+
+func (s *PStorage) DeleteTranslation(ctx context.Context, id int64, language string) error {
+	const op = "storage.postgres.translations.DeleteTranslation"
+
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM song_lyrics_translations
+		WHERE song_id = $1 AND language = $2;
+	`, id, language)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		SELECT id
+		FROM songs
+		WHERE id = $1;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoTranslation))
+}
@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// mapError translates driver errors into the domain taxonomy. notFound is returned in place of
+// sql.ErrNoRows, so every caller can describe the song it was looking for.
+func mapError(err error, notFound error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return &errs.ConstraintError{Err: errs.ErrSongExists, Cause: err}
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return &errs.ConstraintError{Err: errs.ErrSongNotFound, Cause: err}
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return &errs.ConstraintError{Err: errs.ErrInvalidSong, Cause: err}
+	default:
+		return err
+	}
+}
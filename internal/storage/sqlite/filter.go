@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/match"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+)
+
+type queryArgs struct {
+	values []any
+}
+
+func (q *queryArgs) add(value any) string {
+	q.values = append(q.values, value)
+
+	return "?"
+}
+
+func songConditions(filter models.SongFilter, args *queryArgs) ([]string, error) {
+	var conditions []string
+
+	switch {
+	case filter.BandName != "" && filter.Fuzzy:
+		conditions = append(conditions, `trgm_similarity(band, `+args.add(filter.BandName)+`) >= `+args.add(filter.Threshold()))
+	case filter.BandName != "":
+		conditions = append(conditions, matchCondition("band", filter.BandMatch, models.MatchExact, filter.BandName, args))
+	}
+
+	switch {
+	case filter.SongTitle != "" && filter.Fuzzy:
+		conditions = append(conditions, `trgm_similarity(song, `+args.add(filter.SongTitle)+`) >= `+args.add(filter.Threshold()))
+	case filter.SongTitle != "":
+		conditions = append(conditions, matchCondition("song", filter.TitleMatch, models.MatchExact, filter.SongTitle, args))
+	}
+
+	if filter.Lyrics != "" {
+		conditions = append(conditions, matchCondition("lyrics", filter.LyricsMatch, models.MatchContains, filter.Lyrics, args))
+	}
+
+	release, err := releasedate.FilterRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if release.From != nil {
+		conditions = append(conditions, `release >= `+args.add(release.From.Format(isoDate)))
+	}
+
+	if release.To != nil {
+		conditions = append(conditions, `release <= `+args.add(release.To.Format(isoDate)))
+	}
+
+	if filter.HasLink != nil {
+		if *filter.HasLink {
+			conditions = append(conditions, `COALESCE(link, '') <> ''`)
+		} else {
+			conditions = append(conditions, `COALESCE(link, '') = ''`)
+		}
+	}
+
+	return conditions, nil
+}
+
+func matchCondition(column string, mode, def models.MatchMode, pattern string, args *queryArgs) string {
+	kind, insensitive := match.Resolve(mode, def)
+
+	operator := `=`
+	switch kind {
+	case models.MatchPrefix:
+		operator, pattern = `LIKE`, match.EscapeLike(pattern)+"%"
+	case models.MatchContains:
+		operator, pattern = `LIKE`, "%"+match.EscapeLike(pattern)+"%"
+	}
+
+	value := args.add(pattern)
+	if insensitive {
+		column, value = `unicode_lower(`+column+`)`, `unicode_lower(`+value+`)`
+	}
+
+	if operator == `LIKE` {
+		return column + ` LIKE ` + value + ` ESCAPE '\'`
+	}
+
+	return column + ` = ` + value
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/lib/fulltext"
+	"github.com/stepan41k/Testovoe/internal/lib/trigram"
+	"modernc.org/sqlite"
+)
+
+// SQLite's built-in lower() only folds ASCII, so Unicode-aware helpers are registered for every connection.
+// trgm_similarity stands in for pg_trgm's similarity(). The fts_* functions take a search mode, the search text and the lyrics, and stand in for Postgres text search.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1, unicodeLower)
+	sqlite.MustRegisterDeterministicScalarFunction("trgm_similarity", 2, trgmSimilarity)
+	sqlite.MustRegisterDeterministicScalarFunction("fts_match", 3, ftsMatch)
+	sqlite.MustRegisterDeterministicScalarFunction("fts_rank", 3, ftsRank)
+	sqlite.MustRegisterDeterministicScalarFunction("fts_headline", 3, ftsHeadline)
+}
+
+func unicodeLower(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch value := args[0].(type) {
+	case string:
+		return strings.ToLower(value), nil
+	case []byte:
+		return strings.ToLower(string(value)), nil
+	default:
+		return value, nil
+	}
+}
+
+func trgmSimilarity(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	return trigram.Similarity(textArg(args[0]), textArg(args[1])), nil
+}
+
+func ftsMatch(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	query, text := searchArgs(args)
+	if query.Match(text) {
+		return int64(1), nil
+	}
+
+	return int64(0), nil
+}
+
+func ftsRank(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	query, text := searchArgs(args)
+
+	return query.Rank(text), nil
+}
+
+func ftsHeadline(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	query, text := searchArgs(args)
+
+	return query.Headline(text), nil
+}
+
+func searchArgs(args []driver.Value) (fulltext.Query, string) {
+	return fulltext.Parse(fulltext.Mode(textArg(args[0])), textArg(args[1])), textArg(args[2])
+}
+
+// textArg reads a TEXT argument, treating NULL as an empty string.
+func textArg(value driver.Value) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return ""
+	}
+}
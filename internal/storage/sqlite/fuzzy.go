@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// ClosestNames finds the existing band and title most similar to the given ones, for a "did you
+// mean" suggestion. A name is left empty when it was not asked for or nothing reaches threshold.
+func (s *SStorage) ClosestNames(ctx context.Context, band, title string, threshold float64) (models.DidYouMean, error) {
+	const op = "storage.sqlite.fuzzy.ClosestNames"
+
+	var names models.DidYouMean
+
+	for _, name := range []struct {
+		column string
+		value  string
+		to     *string
+	}{
+		{"band", band, &names.BandName},
+		{"song", title, &names.SongTitle},
+	} {
+		if name.value == "" {
+			continue
+		}
+
+		err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT %[1]s
+			FROM songs
+			WHERE trgm_similarity(%[1]s, ?1) >= ?2
+			ORDER BY trgm_similarity(%[1]s, ?1) DESC, %[1]s
+			LIMIT 1;
+		`, name.column), name.value, threshold).Scan(name.to)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return models.DidYouMean{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return names, nil
+}
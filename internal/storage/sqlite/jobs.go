@@ -0,0 +1,254 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+func (s *SStorage) ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (job models.EnrichmentJob, err error) {
+	const op = "storage.sqlite.jobs.ClaimEnrichmentJob"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'running', attempts = attempts + 1,
+			run_at = strftime('%Y-%m-%d %H:%M:%f', 'now', ?), updated = `+now+`
+		WHERE id = (
+			SELECT id
+			FROM enrichment_jobs
+			WHERE state IN ('pending', 'running') AND run_at <= `+now+`
+			ORDER BY run_at, id
+			LIMIT 1
+		)
+		RETURNING id, song_id, attempts;
+	`, modifier(lease)).Scan(&job.ID, &job.SongID, &job.Attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.EnrichmentJob{}, errs.ErrNoJobs
+		}
+
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT band, song
+		FROM songs
+		WHERE id = ?;
+	`, job.SongID).Scan(&job.BandName, &job.SongTitle)
+	if err != nil {
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return job, nil
+}
+
+// CompleteEnrichmentJob marks the job done and fills in the details the song still lacks. Details
+// the client already gave are kept, and blank upstream values leave a field empty.
+func (s *SStorage) CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) (err error) {
+	const op = "storage.sqlite.jobs.CompleteEnrichmentJob"
+
+	var release *string
+	if details.ReleaseDate != "" {
+		date, err := isoRelease(details.ReleaseDate)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		release = &date
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	var songID int64
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'done', last_error = NULL, updated = `+now+`
+		WHERE id = ?
+		RETURNING song_id;
+	`, jobID).Scan(&songID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ErrSongNotFound))
+	}
+
+	var current *string
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT lyrics
+		FROM songs
+		WHERE id = ?;
+	`, songID).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ErrSongNotFound))
+	}
+
+	text := lyrics.Normalize(details.Lyrics)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE songs
+		SET release = COALESCE(release, ?), lyrics = COALESCE(lyrics, NULLIF(?, '')), link = COALESCE(link, NULLIF(?, '')),
+			status = 'enriched', updated = `+now+`
+		WHERE id = ?;
+	`, release, text, details.Link, songID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if current != nil || text == "" {
+		return nil
+	}
+
+	err = replaceVerses(ctx, tx, songID, text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SStorage) RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) error {
+	const op = "storage.sqlite.jobs.RetryEnrichmentJob"
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'pending', run_at = strftime('%Y-%m-%d %H:%M:%f', 'now', ?), last_error = ?, updated = `+now+`
+		WHERE id = ?;
+	`, modifier(delay), reason, jobID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FailEnrichmentJob moves the job to the dead letter state. Its song is marked failed unless the
+// client already gave every detail, in which case nothing is missing and it counts as enriched.
+func (s *SStorage) FailEnrichmentJob(ctx context.Context, jobID int64, reason string) (err error) {
+	const op = "storage.sqlite.jobs.FailEnrichmentJob"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'dead', last_error = ?, updated = `+now+`
+		WHERE id = ?;
+	`, reason, jobID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE songs
+		SET status = CASE WHEN release IS NULL OR lyrics IS NULL OR link IS NULL THEN 'failed' ELSE 'enriched' END,
+			updated = `+now+`
+		WHERE id = (SELECT song_id FROM enrichment_jobs WHERE id = ?);
+	`, jobID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SStorage) RequeueFailedJobs(ctx context.Context) (count int64, err error) {
+	const op = "storage.sqlite.jobs.RequeueFailedJobs"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE songs
+		SET status = 'pending', updated = `+now+`
+		WHERE id IN (SELECT song_id FROM enrichment_jobs WHERE state = 'dead');
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE enrichment_jobs
+		SET state = 'pending', attempts = 0, run_at = `+now+`, last_error = NULL, updated = `+now+`
+		WHERE state = 'dead';
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	count, err = result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func modifier(d time.Duration) string {
+	return fmt.Sprintf("%+.3f seconds", d.Seconds())
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS unique_song_of_band;
+
+DROP TABLE IF EXISTS songs;
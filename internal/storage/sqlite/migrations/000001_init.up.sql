@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS
+    songs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        band TEXT NOT NULL,
+        song TEXT NOT NULL,
+        release TEXT,
+        lyrics TEXT,
+        link TEXT,
+        updated TEXT
+    );
+
+CREATE UNIQUE INDEX unique_song_of_band ON songs(band, song);
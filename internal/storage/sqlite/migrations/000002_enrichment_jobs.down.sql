@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS ready_enrichment_jobs;
+
+DROP INDEX IF EXISTS unique_job_of_song;
+
+DROP TABLE IF EXISTS enrichment_jobs;
+
+ALTER TABLE songs DROP COLUMN status;
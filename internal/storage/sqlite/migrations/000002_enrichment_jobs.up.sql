@@ -0,0 +1,25 @@
+ALTER TABLE songs
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'enriched', 'failed'));
+
+UPDATE songs SET status = 'enriched' WHERE lyrics IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS
+    enrichment_jobs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        state TEXT NOT NULL DEFAULT 'pending'
+            CHECK (state IN ('pending', 'running', 'done', 'dead')),
+        attempts INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT,
+        run_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+        created TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+        updated TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+    );
+
+CREATE UNIQUE INDEX unique_job_of_song ON enrichment_jobs(song_id);
+
+CREATE INDEX ready_enrichment_jobs ON enrichment_jobs(run_at) WHERE state IN ('pending', 'running');
+
+INSERT INTO enrichment_jobs (song_id)
+SELECT id FROM songs WHERE status = 'pending';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS
+    song_verses (
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        position INTEGER NOT NULL CHECK (position > 0),
+        kind TEXT NOT NULL DEFAULT 'verse',
+        text TEXT NOT NULL,
+        PRIMARY KEY (song_id, position)
+    );
@@ -0,0 +1,2 @@
+-- Verses are parsed again from the lyrics at startup. The lyrics themselves were never changed.
+DELETE FROM song_verses;
@@ -0,0 +1,2 @@
+-- Verses are parsed again from the lyrics at startup, now with markers and choruses.
+DELETE FROM song_verses;
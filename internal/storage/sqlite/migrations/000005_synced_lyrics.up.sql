@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS
+    song_synced_lines (
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        position INTEGER NOT NULL CHECK (position > 0),
+        time_ms INTEGER NOT NULL CHECK (time_ms >= 0),
+        text TEXT NOT NULL,
+        PRIMARY KEY (song_id, position)
+    );
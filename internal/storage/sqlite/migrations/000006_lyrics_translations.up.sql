@@ -0,0 +1,10 @@
+ALTER TABLE songs ADD COLUMN language TEXT;
+
+CREATE TABLE IF NOT EXISTS
+    song_lyrics_translations (
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        language TEXT NOT NULL CHECK (language <> ''),
+        lyrics TEXT NOT NULL,
+        updated TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+        PRIMARY KEY (song_id, language)
+    );
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+	"github.com/stepan41k/Testovoe/internal/lib/releasedate"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+func (s *SStorage) GetSongs(ctx context.Context, song models.SongFilter) (models.SongList, error) {
+	const op = "storage.sqlite.music.GetSongs"
+
+	keys, err := sorting.ForSearch(song.Sort, song.Ranked())
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, err := cursor.Decode(song.Cursor, keys)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	args := &queryArgs{}
+	source := songSource(song, args)
+	query := `SELECT id, band, song, strftime('%d.%m.%Y', release), lyrics, link, status, language` + searchColumns(song) + sortColumns(keys) + ` FROM ` + source
+
+	conditions, err := songConditions(song, args)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	where, filterArgs := strings.Join(conditions, " AND "), len(args.values)
+
+	limit, offset := song.PageSize, (song.Page-1)*song.PageSize
+	if limit < 0 || offset < 0 {
+		return models.SongList{}, fmt.Errorf("%s: negative limit or offset", op)
+	}
+
+	if after != nil {
+		conditions = append(conditions, keysetCondition(keys, after, args))
+		offset = 0
+	}
+
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	query += orderBy(keys, after != nil && after.Backward)
+	query += ` LIMIT ` + args.add(limit+1) + ` OFFSET ` + args.add(offset) + `;`
+
+	var total *int64
+	if song.Count {
+		total, err = s.countSongs(ctx, source, where, args.values[:filterArgs])
+		if err != nil {
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args.values...)
+	if err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	type row struct {
+		song   models.Song
+		id     int64
+		values []string
+	}
+
+	var items []row
+	for rows.Next() {
+		var item row
+		var release, lyrics, link, language *string
+
+		item.values = make([]string, len(keys))
+		dest := []any{&item.id, &item.song.BandName, &item.song.SongTitle, &release, &lyrics, &link, &item.song.Status, &language, &item.song.Rank, &item.song.Snippet}
+		for i := range item.values {
+			dest = append(dest, &item.values[i])
+		}
+
+		err = rows.Scan(dest...)
+		if err != nil {
+			return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		item.song.ReleaseDate, item.song.Lyrics, item.song.Link, item.song.Language = deref(release), deref(lyrics), deref(link), deref(language)
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.SongList{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page, next, prev := cursor.Paginate(items, limit, after, offset, keys, func(item row) ([]string, int64) {
+		return item.values, item.id
+	})
+
+	songs := make([]models.Song, 0, len(page))
+	for _, item := range page {
+		item.song.ID = item.id
+		songs = append(songs, item.song)
+	}
+
+	return models.SongList{Songs: songs, NextCursor: next, PrevCursor: prev, Total: total}, nil
+}
+
+// countSongs counts every song of source matching the filter conditions, ignoring paging.
+func (s *SStorage) countSongs(ctx context.Context, source, where string, args []any) (*int64, error) {
+	query := `SELECT COUNT(*) FROM ` + source
+	if where != "" {
+		query += ` WHERE ` + where
+	}
+
+	var total int64
+
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
+
+func (s *SStorage) GetTextSong(ctx context.Context, song models.SongLyrics) (models.Verse, error) {
+	const op = "storage.sqlite.music.GetTextSong"
+
+	verse, err := scanVerse(s.db.QueryRowContext(ctx, `
+		SELECT song_verses.position, song_verses.kind, song_verses.text,
+			(SELECT COUNT(*) FROM song_verses AS counted WHERE counted.song_id = songs.id), songs.language
+		FROM songs
+		JOIN song_verses ON song_verses.song_id = songs.id
+		WHERE songs.song = ? AND songs.band = ? AND song_verses.position = ?;
+	`, song.SongTitle, song.BandName, song.Verse))
+	if err != nil {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)))
+	}
+
+	return verse, nil
+}
+
+func (s *SStorage) DeleteSong(ctx context.Context, song models.Song) (id int64, err error) {
+	const op = "storage.sqlite.music.DeleteSong"
+
+	err = s.db.QueryRowContext(ctx, `
+		DELETE FROM songs
+		WHERE song = ? AND band = ?
+		RETURNING id;
+	`, song.SongTitle, song.BandName).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, mapError(err, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)))
+	}
+
+	return id, nil
+}
+
+// UpdateSong applies every non-empty field of the legacy update body to the song found by band and title.
+func (s *SStorage) UpdateSong(ctx context.Context, song models.Song) (int64, error) {
+	const op = "storage.sqlite.music.UpdateSong"
+
+	notFound := errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)
+
+	updated, err := s.patchSong(ctx, models.LegacyPatch(song), notFound, func(args *queryArgs) string {
+		return `song = ` + args.add(song.SongTitle) + ` AND band = ` + args.add(song.BandName)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated.ID, nil
+}
+
+func (s *SStorage) AddNewSong(ctx context.Context, song models.Song) (id int64, err error) {
+	const op = "storage.sqlite.music.AddNewSong"
+
+	var release *string
+	if song.ReleaseDate != "" {
+		date, err := isoRelease(song.ReleaseDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		release = &date
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	text := lyrics.Normalize(song.Lyrics)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO songs (band, song, release, lyrics, link, language, updated)
+		VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), `+now+`)
+		RETURNING id;
+	`, song.BandName, song.SongTitle, release, text, song.Link, song.Language).Scan(&id)
+	if err != nil {
+		var constraintErr *errs.ConstraintError
+		if err = mapError(err, nil); errors.As(err, &constraintErr) {
+			err = errs.ByName(song.BandName, song.SongTitle, err)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = replaceVerses(ctx, tx, id, text)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO enrichment_jobs (song_id)
+		VALUES (?);
+	`, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func isoRelease(value string) (string, error) {
+	date, err := releasedate.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errs.ErrInvalidSong, err)
+	}
+
+	return date.Format(isoDate), nil
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// songSource returns the relation songs are listed from. A ranked listing adds the rank of every
+// song, so it can be sorted on and paged through like any other column: the score of a lyrics
+// search, which also narrows the songs to the matching ones, plus the similarity of fuzzily
+// matched band and title. There is no stemming here, every search language behaves like "simple".
+func songSource(filter models.SongFilter, args *queryArgs) string {
+	if !filter.Ranked() {
+		return `songs`
+	}
+
+	mode := filter.SearchMode
+	if mode == "" {
+		mode = models.SearchWebsearch
+	}
+
+	// The placeholders are positional, so the fragments are built in the order they appear in the
+	// statement: every score, then the snippet, then the condition.
+	var scores []string
+	snippet, where := `''`, ``
+
+	if filter.Search != "" {
+		scores = append(scores, `fts_rank(`+args.add(string(mode))+`, `+args.add(filter.Search)+`, lyrics)`)
+	}
+
+	if filter.Fuzzy && filter.BandName != "" {
+		scores = append(scores, `trgm_similarity(band, `+args.add(filter.BandName)+`)`)
+	}
+
+	if filter.Fuzzy && filter.SongTitle != "" {
+		scores = append(scores, `trgm_similarity(song, `+args.add(filter.SongTitle)+`)`)
+	}
+
+	if filter.Search != "" {
+		snippet = `fts_headline(` + args.add(string(mode)) + `, ` + args.add(filter.Search) + `, lyrics)`
+		where = ` WHERE fts_match(` + args.add(string(mode)) + `, ` + args.add(filter.Search) + `, lyrics)`
+	}
+
+	return `(
+		SELECT *, ` + strings.Join(scores, ` + `) + ` AS rank, ` + snippet + ` AS snippet
+		FROM songs` + where + `
+	) AS songs`
+}
+
+// searchColumns selects the rank and highlighted snippet of a listed song, zero and empty when
+// there is nothing to rank or highlight.
+func searchColumns(filter models.SongFilter) string {
+	if !filter.Ranked() {
+		return `, 0.0, ''`
+	}
+
+	return `, rank, snippet`
+}
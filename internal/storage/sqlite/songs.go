@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+const songColumns = `id, band, song, strftime('%d.%m.%Y', release), lyrics, link, status, language`
+
+func (s *SStorage) GetSong(ctx context.Context, id int64) (models.Song, error) {
+	const op = "storage.sqlite.songs.GetSong"
+
+	row := s.db.QueryRowContext(ctx, `
+		SELECT `+songColumns+`
+		FROM songs
+		WHERE id = ?;
+	`, id)
+
+	song, err := scanSong(row)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return song, nil
+}
+
+// ReplaceSong overwrites every editable field of the song. Empty fields are stored as NULL.
+func (s *SStorage) ReplaceSong(ctx context.Context, id int64, song models.Song) (replaced models.Song, err error) {
+	const op = "storage.sqlite.songs.ReplaceSong"
+
+	var release *string
+	if song.ReleaseDate != "" {
+		date, err := isoRelease(song.ReleaseDate)
+		if err != nil {
+			return models.Song{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		release = &date
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	text := lyrics.Normalize(song.Lyrics)
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE songs
+		SET band = ?, song = ?, release = ?, lyrics = NULLIF(?, ''), link = NULLIF(?, ''), language = NULLIF(?, ''), updated = `+now+`
+		WHERE id = ?
+		RETURNING `+songColumns+`;
+	`, song.BandName, song.SongTitle, release, text, song.Link, song.Language, id)
+
+	replaced, err = scanSong(row)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	err = replaceVerses(ctx, tx, id, text)
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return replaced, nil
+}
+
+// PatchSong changes the fields set in the patch in one statement and returns the updated song.
+func (s *SStorage) PatchSong(ctx context.Context, id int64, patch models.SongPatch) (models.Song, error) {
+	const op = "storage.sqlite.songs.PatchSong"
+
+	song, err := s.patchSong(ctx, patch, errs.ByID(id, errs.ErrSongNotFound), func(args *queryArgs) string {
+		return `id = ` + args.add(id)
+	})
+	if err != nil {
+		return models.Song{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return song, nil
+}
+
+// patchSong updates the song matched by where. notFound is returned when no song matches.
+func (s *SStorage) patchSong(ctx context.Context, patch models.SongPatch, notFound error, where func(args *queryArgs) string) (song models.Song, err error) {
+	if patch.Empty() {
+		return models.Song{}, errs.ErrNoChanges
+	}
+
+	// Lyrics are stored normalized, and lyrics that normalize to nothing are cleared.
+	if patch.Lyrics.Set && !patch.Lyrics.Null {
+		patch.Lyrics.Value = lyrics.Normalize(patch.Lyrics.Value)
+		patch.Lyrics.Null = patch.Lyrics.Value == ""
+	}
+
+	if patch.ReleaseDate.Set && !patch.ReleaseDate.Null {
+		date, err := isoRelease(patch.ReleaseDate.Value)
+		if err != nil {
+			return models.Song{}, err
+		}
+
+		patch.ReleaseDate.Value = date
+	}
+
+	args := &queryArgs{}
+	assignments := []string{`updated = ` + now}
+
+	for _, field := range []struct {
+		column string
+		value  models.PatchField
+	}{
+		{`band`, patch.BandName},
+		{`song`, patch.SongTitle},
+		{`release`, patch.ReleaseDate},
+		{`lyrics`, patch.Lyrics},
+		{`link`, patch.Link},
+		{`language`, patch.Language},
+	} {
+		switch {
+		case !field.value.Set:
+			continue
+		case field.value.Null:
+			assignments = append(assignments, field.column+` = NULL`)
+		default:
+			assignments = append(assignments, field.column+` = `+args.add(field.value.Value))
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE songs
+		SET `+strings.Join(assignments, ", ")+`
+		WHERE `+where(args)+`
+		RETURNING `+songColumns+`;
+	`, args.values...)
+
+	song, err = scanSong(row)
+	if err != nil {
+		return models.Song{}, mapError(err, notFound)
+	}
+
+	if patch.Lyrics.Set {
+		err = replaceVerses(ctx, tx, song.ID, song.Lyrics)
+		if err != nil {
+			return models.Song{}, err
+		}
+	}
+
+	return song, nil
+}
+
+func (s *SStorage) DeleteSongByID(ctx context.Context, id int64) error {
+	const op = "storage.sqlite.songs.DeleteSongByID"
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM songs
+		WHERE id = ?;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return nil
+}
+
+func scanSong(row *sql.Row) (models.Song, error) {
+	var song models.Song
+	var release, lyrics, link, language *string
+
+	err := row.Scan(&song.ID, &song.BandName, &song.SongTitle, &release, &lyrics, &link, &song.Status, &language)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	song.ReleaseDate, song.Lyrics, song.Link, song.Language = deref(release), deref(lyrics), deref(link), deref(language)
+
+	return song, nil
+}
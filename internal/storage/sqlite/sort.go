@@ -0,0 +1,105 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+	"github.com/stepan41k/Testovoe/internal/lib/sorting"
+)
+
+// sortColumn maps a whitelisted sort field to its SQL expression. Nullable columns are coalesced
+// to a sentinel so that NULLs come last in both directions.
+func sortColumn(key sorting.Key) string {
+	switch key.Field {
+	case sorting.FieldBand:
+		return `band`
+	case sorting.FieldTitle:
+		return `song`
+	case sorting.FieldRelease:
+		if key.Desc {
+			return `COALESCE(release, '0000-01-01')`
+		}
+		return `COALESCE(release, '9999-12-31')`
+	case sorting.FieldUpdated:
+		if key.Desc {
+			return `COALESCE(updated, '0000-01-01 00:00:00.000')`
+		}
+		return `COALESCE(updated, '9999-12-31 23:59:59.999')`
+	case sorting.FieldRank:
+		return `rank`
+	default:
+		return `id`
+	}
+}
+
+func orderBy(keys []sorting.Key, backward bool) string {
+	parts := make([]string, 0, len(keys)+1)
+
+	for _, key := range keys {
+		parts = append(parts, sortColumn(key)+direction(key.Desc != backward))
+	}
+
+	parts = append(parts, `id`+direction(backward))
+
+	return ` ORDER BY ` + strings.Join(parts, ", ")
+}
+
+// sortValue casts a cursor value back to the type of the sort column. Rank is a REAL, and SQLite
+// would otherwise compare it to the text of the cursor value as a string.
+func sortValue(key sorting.Key, placeholder string) string {
+	if key.Field == sorting.FieldRank {
+		return `CAST(` + placeholder + ` AS REAL)`
+	}
+
+	return placeholder
+}
+
+// sortColumns selects the sort expressions, so a cursor can be built from any row.
+func sortColumns(keys []sorting.Key) string {
+	var columns strings.Builder
+
+	for _, key := range keys {
+		columns.WriteString(`, ` + sortColumn(key))
+	}
+
+	return columns.String()
+}
+
+// keysetCondition matches the rows that come after the cursor in sort order, or before it for a backward cursor.
+func keysetCondition(keys []sorting.Key, c *cursor.Cursor, args *queryArgs) string {
+	disjuncts := make([]string, 0, len(keys)+1)
+
+	for i := 0; i <= len(keys); i++ {
+		parts := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			parts = append(parts, sortColumn(keys[j])+` = `+sortValue(keys[j], args.add(c.Values[j])))
+		}
+
+		if i < len(keys) {
+			parts = append(parts, sortColumn(keys[i])+comparison(keys[i].Desc != c.Backward)+sortValue(keys[i], args.add(c.Values[i])))
+		} else {
+			parts = append(parts, `id`+comparison(c.Backward)+args.add(c.ID))
+		}
+
+		disjuncts = append(disjuncts, `(`+strings.Join(parts, ` AND `)+`)`)
+	}
+
+	return `(` + strings.Join(disjuncts, ` OR `) + `)`
+}
+
+func direction(desc bool) string {
+	if desc {
+		return ` DESC`
+	}
+
+	return ` ASC`
+}
+
+func comparison(desc bool) string {
+	if desc {
+		return ` < `
+	}
+
+	return ` > `
+}
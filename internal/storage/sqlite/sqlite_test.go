@@ -0,0 +1,25 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stepan41k/Testovoe/internal/storage/sqlite"
+	"github.com/stepan41k/Testovoe/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	ctx := context.Background()
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		s, err := sqlite.New(ctx, filepath.Join(t.TempDir(), "music.db"))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+
+		t.Cleanup(func() { sqlite.Close(ctx, s) })
+
+		return s
+	})
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+)
+
+// ReplaceSyncedLyrics stores the synced lines of the song in place of the previous ones. Lines are
+// numbered in the order given.
+func (s *SStorage) ReplaceSyncedLyrics(ctx context.Context, id int64, lines []models.SyncedLine) (err error) {
+	const op = "storage.sqlite.synced.ReplaceSyncedLyrics"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE songs
+		SET updated = `+now+`
+		WHERE id = ?
+		RETURNING id;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM song_synced_lines
+		WHERE song_id = ?;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, line := range lines {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO song_synced_lines (song_id, position, time_ms, text)
+			VALUES (?, ?, ?, ?);
+		`, id, i+1, line.TimeMs, line.Text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+		}
+	}
+
+	return nil
+}
+
+// GetSyncedLyrics returns the synced lines of the song in order.
+func (s *SStorage) GetSyncedLyrics(ctx context.Context, id int64) ([]models.SyncedLine, error) {
+	const op = "storage.sqlite.synced.GetSyncedLyrics"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT song_synced_lines.position, song_synced_lines.time_ms, song_synced_lines.text
+		FROM songs
+		LEFT JOIN song_synced_lines ON song_synced_lines.song_id = songs.id
+		WHERE songs.id = ?
+		ORDER BY song_synced_lines.position;
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	found := false
+	var lines []models.SyncedLine
+
+	for rows.Next() {
+		var position *int
+		var timeMs *int64
+		var text *string
+
+		if err = rows.Scan(&position, &timeMs, &text); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		found = true
+		if position != nil {
+			lines = append(lines, models.SyncedLine{Number: *position, TimeMs: *timeMs, Text: deref(text)})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch {
+	case !found:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	case len(lines) == 0:
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+	}
+
+	return lines, nil
+}
+
+// DeleteSyncedLyrics removes the synced lines of the song, leaving its plain lyrics alone.
+func (s *SStorage) DeleteSyncedLyrics(ctx context.Context, id int64) error {
+	const op = "storage.sqlite.synced.DeleteSyncedLyrics"
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM song_synced_lines
+		WHERE song_id = ?;
+	`, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected > 0 {
+		return nil
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id
+		FROM songs
+		WHERE id = ?;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoSyncedLyrics))
+}
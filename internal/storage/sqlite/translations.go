@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+// SaveTranslation adds the translation of the song's lyrics to the language, or replaces the one
+// it has. created reports whether the song had no translation to the language before.
+func (s *SStorage) SaveTranslation(ctx context.Context, id int64, language, text string) (created bool, err error) {
+	const op = "storage.sqlite.translations.SaveTranslation"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			err = fmt.Errorf("%s: %w", op, commitErr)
+		}
+	}()
+
+	var exists bool
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM song_lyrics_translations WHERE song_id = songs.id AND language = ?)
+		FROM songs
+		WHERE id = ?;
+	`, language, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO song_lyrics_translations (song_id, language, lyrics, updated)
+		VALUES (?, ?, ?, `+now+`)
+		ON CONFLICT (song_id, language) DO UPDATE
+		SET lyrics = excluded.lyrics, updated = excluded.updated;
+	`, id, language, lyrics.Normalize(text))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return !exists, nil
+}
+
+func (s *SStorage) GetTranslation(ctx context.Context, id int64, language string) (models.Translation, error) {
+	const op = "storage.sqlite.translations.GetTranslation"
+
+	translation, err := s.translation(ctx, `songs.id = ?`, language, id)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByID(id, err))
+	}
+
+	return translation, nil
+}
+
+// GetTextTranslation returns the translation to song.Lang of the song found by band and title.
+func (s *SStorage) GetTextTranslation(ctx context.Context, song models.SongLyrics) (models.Translation, error) {
+	const op = "storage.sqlite.translations.GetTextTranslation"
+
+	translation, err := s.translation(ctx, `songs.song = ? AND songs.band = ?`, song.Lang, song.SongTitle, song.BandName)
+	if err != nil {
+		return models.Translation{}, fmt.Errorf("%s: %w", op, errs.ByName(song.BandName, song.SongTitle, err))
+	}
+
+	return translation, nil
+}
+
+// translation returns the translation to language of the song matched by where. It fails with
+// errs.ErrSongNotFound or errs.ErrNoTranslation, for the caller to tie to the song.
+func (s *SStorage) translation(ctx context.Context, where, language string, args ...any) (models.Translation, error) {
+	translation := models.Translation{Language: language}
+	var text *string
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT songs.id, song_lyrics_translations.lyrics
+		FROM songs
+		LEFT JOIN song_lyrics_translations
+			ON song_lyrics_translations.song_id = songs.id AND song_lyrics_translations.language = ?
+		WHERE `+where+`;
+	`, append([]any{language}, args...)...).Scan(&translation.SongID, &text)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.Translation{}, errs.ErrSongNotFound
+	case err != nil:
+		return models.Translation{}, err
+	case text == nil:
+		return models.Translation{}, errs.ErrNoTranslation
+	}
+
+	translation.Lyrics = *text
+
+	return translation, nil
+}
+
+// GetTranslations returns every translation of the song, ordered by language.
+func (s *SStorage) GetTranslations(ctx context.Context, id int64) ([]models.Translation, error) {
+	const op = "storage.sqlite.translations.GetTranslations"
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT song_lyrics_translations.language, song_lyrics_translations.lyrics
+		FROM songs
+		LEFT JOIN song_lyrics_translations ON song_lyrics_translations.song_id = songs.id
+		WHERE songs.id = ?
+		ORDER BY song_lyrics_translations.language;
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	defer rows.Close()
+
+	found := false
+	translations := []models.Translation{}
+
+	for rows.Next() {
+		var language, text *string
+
+		if err = rows.Scan(&language, &text); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		found = true
+		if language != nil {
+			translations = append(translations, models.Translation{SongID: id, Language: *language, Lyrics: deref(text)})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrSongNotFound))
+	}
+
+	return translations, nil
+}
+
+func (s *SStorage) DeleteTranslation(ctx context.Context, id int64, language string) error {
+	const op = "storage.sqlite.translations.DeleteTranslation"
+
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM song_lyrics_translations
+		WHERE song_id = ? AND language = ?;
+	`, id, language)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if affected > 0 {
+		return nil
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id
+		FROM songs
+		WHERE id = ?;
+	`, id).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return fmt.Errorf("%s: %w", op, errs.ByID(id, errs.ErrNoTranslation))
+}
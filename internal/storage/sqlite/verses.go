@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/lyrics"
+)
+
+// GetVerse returns the n-th verse of the song, counting from one.
+func (s *SStorage) GetVerse(ctx context.Context, id int64, n int) (models.Verse, error) {
+	const op = "storage.sqlite.verses.GetVerse"
+
+	verse, err := scanVerse(s.db.QueryRowContext(ctx, `
+		SELECT song_verses.position, song_verses.kind, song_verses.text,
+			(SELECT COUNT(*) FROM song_verses AS counted WHERE counted.song_id = songs.id), songs.language
+		FROM songs
+		JOIN song_verses ON song_verses.song_id = songs.id
+		WHERE songs.id = ?1 AND song_verses.position = ?2;
+	`, id, n))
+	if err != nil {
+		return models.Verse{}, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return verse, nil
+}
+
+// GetVerses returns every verse of the song in order. A song without lyrics has no verses.
+func (s *SStorage) GetVerses(ctx context.Context, id int64) ([]models.Verse, error) {
+	const op = "storage.sqlite.verses.GetVerses"
+
+	verses, err := s.listVerses(ctx, `songs.id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, mapError(err, errs.ByID(id, errs.ErrSongNotFound)))
+	}
+
+	return verses, nil
+}
+
+// GetTextVerses returns every verse of the song found by band and title in order.
+func (s *SStorage) GetTextVerses(ctx context.Context, song models.SongLyrics) ([]models.Verse, error) {
+	const op = "storage.sqlite.verses.GetTextVerses"
+
+	verses, err := s.listVerses(ctx, `songs.song = ? AND songs.band = ?`, song.SongTitle, song.BandName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, mapError(err, errs.ByName(song.BandName, song.SongTitle, errs.ErrSongNotFound)))
+	}
+
+	return verses, nil
+}
+
+// listVerses returns the verses of the song matched by where, or sql.ErrNoRows when there is no
+// such song.
+func (s *SStorage) listVerses(ctx context.Context, where string, args ...any) ([]models.Verse, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT song_verses.position, song_verses.kind, song_verses.text, songs.language
+		FROM songs
+		LEFT JOIN song_verses ON song_verses.song_id = songs.id
+		WHERE `+where+`
+		ORDER BY song_verses.position;
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	found := false
+	verses := []models.Verse{}
+
+	for rows.Next() {
+		var position *int
+		var kind, text, language *string
+
+		if err = rows.Scan(&position, &kind, &text, &language); err != nil {
+			return nil, err
+		}
+
+		found = true
+		if position != nil {
+			verses = append(verses, models.Verse{Number: *position, Kind: deref(kind), Text: deref(text), Language: deref(language)})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+
+	for i := range verses {
+		verses[i].Total = len(verses)
+	}
+
+	return verses, nil
+}
+
+func scanVerse(row *sql.Row) (models.Verse, error) {
+	var verse models.Verse
+	var language *string
+
+	if err := row.Scan(&verse.Number, &verse.Kind, &verse.Text, &verse.Total, &language); err != nil {
+		return models.Verse{}, err
+	}
+
+	verse.Language = deref(language)
+
+	return verse, nil
+}
+
+// replaceVerses stores the verses of the song's lyrics, which must already be normalized, in
+// place of the previous ones.
+func replaceVerses(ctx context.Context, tx *sql.Tx, id int64, text string) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM song_verses
+		WHERE song_id = ?;
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	for i, verse := range lyrics.Parse(text) {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO song_verses (song_id, position, kind, text)
+			VALUES (?, ?, ?, ?);
+		`, id, i+1, verse.Kind, verse.Text)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillVerses parses the lyrics of songs stored before verses were. The stored lyrics are left
+// as they are and only normalized the next time they are written.
+func backfillVerses(ctx context.Context, db *sql.DB) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, lyrics
+		FROM songs
+		WHERE lyrics IS NOT NULL AND id NOT IN (SELECT song_id FROM song_verses);
+	`)
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var text string
+
+		if err = rows.Scan(&id, &text); err != nil {
+			_ = rows.Close()
+			return err
+		}
+
+		pending[id] = lyrics.Normalize(text)
+	}
+
+	if err = rows.Close(); err != nil {
+		return err
+	}
+
+	for id, text := range pending {
+		if err = replaceVerses(ctx, tx, id, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,9 +0,0 @@
-package storage
-
-import "errors"
-
-var (
-	ErrSongExists = errors.New("song already exists")
-	ErrSongNotFound = errors.New("song not found")
-	ErrNoChanges = errors.New("no changes")
-)
\ No newline at end of file
@@ -0,0 +1,1356 @@
+// Package storagetest holds the conformance suite every storage backend has to pass.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stepan41k/Testovoe/internal/domain/errs"
+	"github.com/stepan41k/Testovoe/internal/domain/models"
+	"github.com/stepan41k/Testovoe/internal/lib/cursor"
+)
+
+type Storage interface {
+	GetSongs(ctx context.Context, song models.SongFilter) (songs models.SongList, err error)
+	GetTextSong(ctx context.Context, song models.SongLyrics) (verse models.Verse, err error)
+	DeleteSong(ctx context.Context, song models.Song) (id int64, err error)
+	UpdateSong(ctx context.Context, songDetails models.Song) (id int64, err error)
+	AddNewSong(ctx context.Context, song models.Song) (id int64, err error)
+	GetSong(ctx context.Context, id int64) (song models.Song, err error)
+	ReplaceSong(ctx context.Context, id int64, song models.Song) (replaced models.Song, err error)
+	PatchSong(ctx context.Context, id int64, patch models.SongPatch) (patched models.Song, err error)
+	DeleteSongByID(ctx context.Context, id int64) error
+	GetVerse(ctx context.Context, id int64, n int) (verse models.Verse, err error)
+	GetVerses(ctx context.Context, id int64) (verses []models.Verse, err error)
+	GetTextVerses(ctx context.Context, song models.SongLyrics) (verses []models.Verse, err error)
+	ReplaceSyncedLyrics(ctx context.Context, id int64, lines []models.SyncedLine) error
+	GetSyncedLyrics(ctx context.Context, id int64) (lines []models.SyncedLine, err error)
+	DeleteSyncedLyrics(ctx context.Context, id int64) error
+	SaveTranslation(ctx context.Context, id int64, language, text string) (created bool, err error)
+	GetTranslation(ctx context.Context, id int64, language string) (translation models.Translation, err error)
+	GetTextTranslation(ctx context.Context, song models.SongLyrics) (translation models.Translation, err error)
+	GetTranslations(ctx context.Context, id int64) (translations []models.Translation, err error)
+	DeleteTranslation(ctx context.Context, id int64, language string) error
+	ClosestNames(ctx context.Context, band, title string, threshold float64) (names models.DidYouMean, err error)
+	Names(ctx context.Context, kind models.NameKind) (names []models.Name, err error)
+	ClaimEnrichmentJob(ctx context.Context, lease time.Duration) (job models.EnrichmentJob, err error)
+	CompleteEnrichmentJob(ctx context.Context, jobID int64, details models.Song) (err error)
+	RetryEnrichmentJob(ctx context.Context, jobID int64, delay time.Duration, reason string) (err error)
+	FailEnrichmentJob(ctx context.Context, jobID int64, reason string) (err error)
+	RequeueFailedJobs(ctx context.Context) (count int64, err error)
+}
+
+// Run executes the suite. newStorage must return an empty storage on every call.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s Storage)
+	}{
+		{"AddNewSong/Unique", testAddUnique},
+		{"AddNewSong/Pending", testAddPending},
+		{"GetSongs/Paging", testPaging},
+		{"GetSongs/FilterByTitleAndBand", testFilterByName},
+		{"GetSongs/ReleaseDate", testReleaseDate},
+		{"GetSongs/ReleaseRange", testReleaseRange},
+		{"GetSongs/CombinedFilters", testCombinedFilters},
+		{"GetSongs/Sort", testSort},
+		{"GetSongs/MatchModes", testMatchModes},
+		{"GetSongs/Count", testCount},
+		{"GetSongs/Cursor", testCursor},
+		{"GetSongs/CursorConcurrentInsert", testCursorInsert},
+		{"GetSongs/Search", testSearch},
+		{"GetSongs/Fuzzy", testFuzzy},
+		{"ClosestNames", testClosestNames},
+		{"Names", testNames},
+		{"GetTextSong/Verses", testVerses},
+		{"GetTextSong/NotFound", testVersesNotFound},
+		{"GetVerse/FollowsWrites", testVersesFollowWrites},
+		{"GetVerses/Kinds", testVerseKinds},
+		{"SyncedLyrics", testSyncedLyrics},
+		{"Translations", testTranslations},
+		{"Jobs/Complete", testJobsComplete},
+		{"Jobs/Lease", testJobsLease},
+		{"Jobs/Retry", testJobsRetry},
+		{"Jobs/DeadLetter", testJobsDeadLetter},
+		{"UpdateSong/EveryField", testUpdateEveryField},
+		{"UpdateSong/Errors", testUpdateErrors},
+		{"UpdateSong/SeveralFields", testUpdateSeveralFields},
+		{"DeleteSong", testDelete},
+		{"GetSong", testGetSong},
+		{"ReplaceSong", testReplaceSong},
+		{"PatchSong", testPatchSong},
+		{"PatchSong/Errors", testPatchErrors},
+		{"PatchSong/Language", testPatchLanguage},
+		{"DeleteSongByID", testDeleteByID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func testAddUnique(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	_, err := s.AddNewSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if !errors.Is(err, errs.ErrSongExists) {
+		t.Fatalf("AddNewSong duplicate: got %v, want %v", err, errs.ErrSongExists)
+	}
+
+	var songErr *errs.SongError
+	if !errors.As(err, &songErr) || songErr.BandName != "Muse" || songErr.SongTitle != "Starlight" {
+		t.Fatalf("AddNewSong duplicate: got %v, want a SongError for Muse - Starlight", err)
+	}
+
+	second := add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising"})
+	third := add(t, s, models.Song{BandName: "Kasabian", SongTitle: "Starlight"})
+
+	if first == second || second == third || first == third {
+		t.Fatalf("AddNewSong returned duplicate ids: %d, %d, %d", first, second, third)
+	}
+}
+
+func testAddPending(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	songs := list(t, s, models.SongFilter{SongTitle: "Starlight"})
+	if len(songs) != 1 {
+		t.Fatalf("GetSongs: got %d songs, want 1", len(songs))
+	}
+
+	if songs[0].Status != models.SongStatusPending {
+		t.Fatalf("new song status: got %q, want %q", songs[0].Status, models.SongStatusPending)
+	}
+}
+
+func testPaging(t *testing.T, s Storage) {
+	for i := 1; i <= 5; i++ {
+		add(t, s, models.Song{BandName: "Band", SongTitle: fmt.Sprintf("Song %d", i)})
+	}
+
+	seen := map[string]bool{}
+	for page, want := range map[int]int{1: 2, 2: 2, 3: 1, 4: 0} {
+		songs := list(t, s, models.SongFilter{Page: page, PageSize: 2})
+		if len(songs) != want {
+			t.Fatalf("page %d: got %d songs, want %d", page, len(songs), want)
+		}
+
+		for _, song := range songs {
+			if seen[song.SongTitle] {
+				t.Fatalf("page %d: song %q returned twice", page, song.SongTitle)
+			}
+			seen[song.SongTitle] = true
+		}
+	}
+
+	if len(seen) != 5 {
+		t.Fatalf("pages returned %d distinct songs, want 5", len(seen))
+	}
+
+	if songs := list(t, s, models.SongFilter{Page: 1, PageSize: 10}); len(songs) != 5 {
+		t.Fatalf("page larger than library: got %d songs, want 5", len(songs))
+	}
+}
+
+func testFilterByName(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising"})
+	add(t, s, models.Song{BandName: "Kasabian", SongTitle: "Fire"})
+
+	if songs := list(t, s, models.SongFilter{BandName: "Muse", Page: 1, PageSize: 10}); len(songs) != 2 {
+		t.Fatalf("filter by band: got %d songs, want 2", len(songs))
+	}
+
+	songs := list(t, s, models.SongFilter{SongTitle: "Fire", Page: 1, PageSize: 10})
+	if len(songs) != 1 || songs[0].BandName != "Kasabian" {
+		t.Fatalf("filter by title: got %+v", songs)
+	}
+
+	if songs := list(t, s, models.SongFilter{BandName: "Nobody", Page: 1, PageSize: 10}); len(songs) != 0 {
+		t.Fatalf("filter by unknown band: got %d songs, want 0", len(songs))
+	}
+}
+
+func testReleaseDate(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Showbiz", ReleaseDate: "27.09.1999"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", ReleaseDate: "16.07.2006"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", ReleaseDate: "07.09.2009"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Unreleased"})
+
+	after := titles(list(t, s, models.SongFilter{ReleaseDate: "16.07.2006", Later: true, Page: 1, PageSize: 10}))
+	if want := []string{"Uprising"}; !sameSet(after, want) {
+		t.Fatalf("released after: got %v, want %v", after, want)
+	}
+
+	before := titles(list(t, s, models.SongFilter{ReleaseDate: "16.07.2006", Page: 1, PageSize: 10}))
+	if want := []string{"Showbiz", "Starlight"}; !sameSet(before, want) {
+		t.Fatalf("released before: got %v, want %v", before, want)
+	}
+
+	songs := list(t, s, models.SongFilter{SongTitle: "Starlight", Page: 1, PageSize: 10})
+	if len(songs) != 1 || songs[0].ReleaseDate != "16.07.2006" {
+		t.Fatalf("release date round trip: got %+v", songs)
+	}
+}
+
+func testReleaseRange(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Showbiz", ReleaseDate: "27.09.1999"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Time Is Running Out", ReleaseDate: "08.09.2003"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", ReleaseDate: "03.09.2006"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Supermassive Black Hole", ReleaseDate: "16.07.2006"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", ReleaseDate: "07.09.2009"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Madness", ReleaseDate: "20.08.2012"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Unreleased"})
+
+	tests := []struct {
+		filter models.SongFilter
+		want   []string
+	}{
+		{models.SongFilter{ReleaseFrom: "2000", ReleaseTo: "2010"}, []string{"Time Is Running Out", "Starlight", "Supermassive Black Hole", "Uprising"}},
+		{models.SongFilter{ReleaseFrom: "2006", ReleaseTo: "2006"}, []string{"Starlight", "Supermassive Black Hole"}},
+		{models.SongFilter{ReleaseFrom: "09.2006", ReleaseTo: "09.2006"}, []string{"Starlight"}},
+		{models.SongFilter{ReleaseFrom: "2006", ReleaseFromExclusive: true}, []string{"Uprising", "Madness"}},
+		{models.SongFilter{ReleaseTo: "2006", ReleaseToExclusive: true}, []string{"Showbiz", "Time Is Running Out"}},
+		{models.SongFilter{ReleaseFrom: "16.07.2006", ReleaseTo: "07.09.2009"}, []string{"Supermassive Black Hole", "Starlight", "Uprising"}},
+		{models.SongFilter{ReleaseFrom: "16.07.2006", ReleaseFromExclusive: true, ReleaseTo: "07.09.2009", ReleaseToExclusive: true}, []string{"Starlight"}},
+		{models.SongFilter{ReleaseFrom: "2000", ReleaseDate: "16.07.2006"}, []string{"Time Is Running Out", "Supermassive Black Hole"}},
+	}
+
+	for _, tt := range tests {
+		if got := titles(list(t, s, tt.filter)); !sameSet(got, tt.want) {
+			t.Fatalf("filter %+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	for _, filter := range []models.SongFilter{
+		{ReleaseFrom: "2006-07-16", Page: 1, PageSize: 10},
+		{ReleaseTo: "13.2006", Page: 1, PageSize: 10},
+		{ReleaseFrom: "2010", ReleaseTo: "2000", Page: 1, PageSize: 10},
+	} {
+		if _, err := s.GetSongs(ctx, filter); err == nil {
+			t.Fatalf("filter %+v: expected an error", filter)
+		}
+	}
+}
+
+func testCombinedFilters(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Supermassive Black Hole", ReleaseDate: "16.07.2006", Lyrics: "You set my soul alight", Link: "https://example.com/smbh"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Showbiz", ReleaseDate: "27.09.1999", Lyrics: "Controlling my soul"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", ReleaseDate: "07.09.2009", Lyrics: "They will not force us"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Song to Say Goodbye", ReleaseDate: "13.03.2006", Lyrics: "a soul to sell"})
+
+	filter := models.SongFilter{BandName: "Muse", ReleaseDate: "01.01.2005", Later: true, Lyrics: "soul"}
+	if got, want := titles(list(t, s, filter)), []string{"Supermassive Black Hole"}; !sameSet(got, want) {
+		t.Fatalf("band, release and lyrics: got %v, want %v", got, want)
+	}
+
+	filter = models.SongFilter{BandName: "Muse", Lyrics: "soul"}
+	if got, want := titles(list(t, s, filter)), []string{"Supermassive Black Hole", "Showbiz"}; !sameSet(got, want) {
+		t.Fatalf("band and lyrics: got %v, want %v", got, want)
+	}
+
+	hasLink, noLink := true, false
+
+	filter = models.SongFilter{BandName: "Muse", HasLink: &hasLink}
+	if got, want := titles(list(t, s, filter)), []string{"Supermassive Black Hole"}; !sameSet(got, want) {
+		t.Fatalf("with link: got %v, want %v", got, want)
+	}
+
+	filter = models.SongFilter{Lyrics: "soul", HasLink: &noLink}
+	if got, want := titles(list(t, s, filter)), []string{"Showbiz", "Song to Say Goodbye"}; !sameSet(got, want) {
+		t.Fatalf("without link: got %v, want %v", got, want)
+	}
+}
+
+func testMatchModes(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "Far away\nThis ship"})
+	add(t, s, models.Song{BandName: "Museum", SongTitle: "Star_Light"})
+	add(t, s, models.Song{BandName: "The Muse", SongTitle: "100% Starlight"})
+	add(t, s, models.Song{BandName: "Кино", SongTitle: "Группа крови", Lyrics: "Пожелай мне удачи"})
+
+	tests := []struct {
+		filter models.SongFilter
+		want   []string
+	}{
+		{models.SongFilter{BandName: "Muse"}, []string{"Starlight"}},
+		{models.SongFilter{BandName: "muse", BandMatch: models.MatchIExact}, []string{"Starlight"}},
+		{models.SongFilter{BandName: "Muse", BandMatch: models.MatchPrefix}, []string{"Starlight", "Star_Light"}},
+		{models.SongFilter{BandName: "Muse", BandMatch: models.MatchContains}, []string{"Starlight", "Star_Light", "100% Starlight"}},
+		{models.SongFilter{BandName: "muse", BandMatch: models.MatchPrefix}, nil},
+		{models.SongFilter{BandName: "MUSE", BandMatch: models.MatchIPrefix}, []string{"Starlight", "Star_Light"}},
+		{models.SongFilter{SongTitle: "Star_", TitleMatch: models.MatchPrefix}, []string{"Star_Light"}},
+		{models.SongFilter{SongTitle: "0%", TitleMatch: models.MatchContains}, []string{"100% Starlight"}},
+		{models.SongFilter{SongTitle: "STARLIGHT", TitleMatch: models.MatchIContains}, []string{"Starlight", "100% Starlight"}},
+		{models.SongFilter{SongTitle: "ГРУППА", TitleMatch: models.MatchIPrefix}, []string{"Группа крови"}},
+		{models.SongFilter{Lyrics: "удачи"}, []string{"Группа крови"}},
+		{models.SongFilter{Lyrics: "FAR", LyricsMatch: models.MatchIPrefix}, []string{"Starlight"}},
+		{models.SongFilter{Lyrics: "far", LyricsMatch: models.MatchContains}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := titles(list(t, s, tt.filter)); !sameSet(got, tt.want) {
+			t.Fatalf("filter %+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func testSort(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", ReleaseDate: "07.09.2009"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Bitter End", ReleaseDate: "17.03.2003"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", ReleaseDate: "03.09.2006"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Running Up That Hill", ReleaseDate: "03.09.2006"})
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Uprising", "Bitter End", "Hysteria", "Starlight", "Running Up That Hill"}},
+		{"song_title", []string{"Bitter End", "Hysteria", "Running Up That Hill", "Starlight", "Uprising"}},
+		{"-song_title", []string{"Uprising", "Starlight", "Running Up That Hill", "Hysteria", "Bitter End"}},
+		{"release_date", []string{"Bitter End", "Starlight", "Running Up That Hill", "Uprising", "Hysteria"}},
+		{"-release_date", []string{"Uprising", "Starlight", "Running Up That Hill", "Bitter End", "Hysteria"}},
+		{"band_name,-release_date", []string{"Uprising", "Starlight", "Hysteria", "Running Up That Hill", "Bitter End"}},
+		{"-band_name,song_title", []string{"Bitter End", "Running Up That Hill", "Hysteria", "Starlight", "Uprising"}},
+	}
+
+	for _, tt := range tests {
+		if got := titles(list(t, s, models.SongFilter{Sort: tt.sort})); !slices.Equal(got, tt.want) {
+			t.Fatalf("sort %q: got %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+
+	// Backends store update time with millisecond precision at worst.
+	time.Sleep(10 * time.Millisecond)
+
+	if _, err := s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Hysteria", Link: "https://example.com"}); err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+
+	if got := titles(list(t, s, models.SongFilter{Sort: "-updated"})); got[0] != "Hysteria" {
+		t.Fatalf("sort by last update: got %v, want Hysteria first", got)
+	}
+
+	for _, sort := range []string{"id", "band_name;DROP TABLE songs", "band_name,band_name", "-"} {
+		if _, err := s.GetSongs(ctx, models.SongFilter{Sort: sort, Page: 1, PageSize: 10}); err == nil {
+			t.Fatalf("sort %q: expected an error", sort)
+		}
+	}
+}
+
+func testCount(t *testing.T, s Storage) {
+	for i := 1; i <= 5; i++ {
+		add(t, s, models.Song{BandName: "Band", SongTitle: fmt.Sprintf("Song %d", i)})
+	}
+	add(t, s, models.Song{BandName: "Other", SongTitle: "Song 6"})
+
+	if got := page(t, s, models.SongFilter{Page: 1, PageSize: 2}); got.Total != nil {
+		t.Fatalf("count not requested: got total %d", *got.Total)
+	}
+
+	for _, filter := range []models.SongFilter{
+		{BandName: "Band", Page: 1, PageSize: 2, Count: true},
+		{BandName: "Band", Page: 3, PageSize: 2, Count: true},
+		{BandName: "Band", Page: 1, PageSize: 2, Count: true, Cursor: page(t, s, models.SongFilter{BandName: "Band", Page: 1, PageSize: 2}).NextCursor},
+	} {
+		got := page(t, s, filter)
+		if got.Total == nil || *got.Total != 5 {
+			t.Fatalf("count %+v: got %v, want 5", filter, got.Total)
+		}
+	}
+}
+
+func testCursor(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", ReleaseDate: "07.09.2009"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Bitter End", ReleaseDate: "17.03.2003"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", ReleaseDate: "03.09.2006"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Running Up That Hill", ReleaseDate: "03.09.2006"})
+
+	for _, sort := range []string{"", "release_date", "-release_date", "band_name,-release_date", "-updated"} {
+		want := titles(list(t, s, models.SongFilter{Sort: sort}))
+
+		var forward []string
+		var last models.SongList
+
+		filter := models.SongFilter{Sort: sort, Page: 1, PageSize: 2}
+		for {
+			last = page(t, s, filter)
+			forward = append(forward, titles(last.Songs)...)
+
+			if last.NextCursor == "" {
+				break
+			}
+
+			filter.Cursor = last.NextCursor
+		}
+
+		if !slices.Equal(forward, want) {
+			t.Fatalf("sort %q forward: got %v, want %v", sort, forward, want)
+		}
+
+		backward := titles(last.Songs)
+		for last.PrevCursor != "" {
+			last = page(t, s, models.SongFilter{Sort: sort, Page: 1, PageSize: 2, Cursor: last.PrevCursor})
+			backward = append(titles(last.Songs), backward...)
+		}
+
+		if !slices.Equal(backward, want) {
+			t.Fatalf("sort %q backward: got %v, want %v", sort, backward, want)
+		}
+	}
+
+	first := page(t, s, models.SongFilter{Page: 1, PageSize: 2})
+	if first.PrevCursor != "" {
+		t.Fatalf("first page: got a previous cursor")
+	}
+
+	if second := page(t, s, models.SongFilter{Page: 2, PageSize: 2}); second.PrevCursor == "" || second.NextCursor == "" {
+		t.Fatalf("second page by number: want both cursors, got %+v", second)
+	}
+
+	for _, token := range []string{"garbage", first.NextCursor} {
+		_, err := s.GetSongs(ctx, models.SongFilter{Sort: "song_title", Page: 1, PageSize: 2, Cursor: token})
+		if !errors.Is(err, cursor.ErrInvalidCursor) {
+			t.Fatalf("cursor %q with another sort: got %v, want ErrInvalidCursor", token, err)
+		}
+	}
+
+	for _, tampered := range []cursor.Cursor{
+		{Sort: "release_date", Values: []string{"03.09.2006"}, ID: 1},
+		{Sort: "-updated", Values: []string{"yesterday"}, ID: 1},
+		{Sort: "song_title", Values: []string{"A\x00"}, ID: 1},
+	} {
+		_, err := s.GetSongs(ctx, models.SongFilter{Sort: tampered.Sort, Page: 1, PageSize: 2, Cursor: cursor.Encode(tampered)})
+		if !errors.Is(err, cursor.ErrInvalidCursor) {
+			t.Fatalf("tampered cursor %+v: got %v, want ErrInvalidCursor", tampered, err)
+		}
+	}
+}
+
+func testCursorInsert(t *testing.T, s Storage) {
+	for _, title := range []string{"B", "D", "F", "H"} {
+		add(t, s, models.Song{BandName: "Band", SongTitle: title})
+	}
+
+	first := page(t, s, models.SongFilter{Sort: "song_title", Page: 1, PageSize: 2})
+
+	add(t, s, models.Song{BandName: "Band", SongTitle: "A"})
+	add(t, s, models.Song{BandName: "Band", SongTitle: "C"})
+	add(t, s, models.Song{BandName: "Band", SongTitle: "E"})
+
+	second := page(t, s, models.SongFilter{Sort: "song_title", Page: 1, PageSize: 2, Cursor: first.NextCursor})
+
+	got := append(titles(first.Songs), titles(second.Songs)...)
+	if want := []string{"B", "D", "E", "F"}; !slices.Equal(got, want) {
+		t.Fatalf("pages around inserts: got %v, want %v", got, want)
+	}
+}
+
+func testSearch(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "Far away, this ship is taking me far away"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", Lyrics: "It's bugging me, grating me"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising", Lyrics: "They will not force us, they will stop degrading us, far from home"})
+	add(t, s, models.Song{BandName: "Кино", SongTitle: "Кукушка", Lyrics: "Песен ещё ненаписанных сколько"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Instrumental"})
+
+	tests := []struct {
+		filter models.SongFilter
+		want   []string
+	}{
+		{models.SongFilter{Search: "far away", SearchMode: models.SearchPlain}, []string{"Starlight"}},
+		{models.SongFilter{Search: "FAR"}, []string{"Starlight", "Uprising"}},
+		{models.SongFilter{Search: "taking me far", SearchMode: models.SearchPhrase}, []string{"Starlight"}},
+		{models.SongFilter{Search: "away far", SearchMode: models.SearchPhrase}, nil},
+		{models.SongFilter{Search: "far -ship"}, []string{"Uprising"}},
+		{models.SongFilter{Search: `"far away" or bugging`}, []string{"Starlight", "Hysteria"}},
+		{models.SongFilter{Search: "ненаписанных"}, []string{"Кукушка"}},
+		{models.SongFilter{Search: "far", BandName: "Кино"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := titles(list(t, s, tt.filter)); !sameSet(got, tt.want) {
+			t.Fatalf("search %+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	ranked := page(t, s, models.SongFilter{Search: "far", Page: 1, PageSize: 1, Count: true})
+	if got := titles(ranked.Songs); !slices.Equal(got, []string{"Starlight"}) || ranked.Songs[0].Rank <= 0 {
+		t.Fatalf("best match first: got %v with rank %v", got, ranked.Songs)
+	}
+
+	if ranked.Total == nil || *ranked.Total != 2 {
+		t.Fatalf("search count: got %v, want 2", ranked.Total)
+	}
+
+	next := page(t, s, models.SongFilter{Search: "far", Page: 1, PageSize: 1, Cursor: ranked.NextCursor})
+	if got := titles(next.Songs); !slices.Equal(got, []string{"Uprising"}) || next.NextCursor != "" {
+		t.Fatalf("second page by rank: got %v", got)
+	}
+
+	if got := list(t, s, models.SongFilter{Search: "ship"}); len(got) != 1 || !strings.Contains(got[0].Snippet, "<b>ship</b>") {
+		t.Fatalf("snippet: got %+v", got)
+	}
+
+	if _, err := s.GetSongs(ctx, models.SongFilter{Sort: "-rank", Page: 1, PageSize: 10}); err == nil {
+		t.Fatalf("sort by rank without a search: expected an error")
+	}
+}
+
+func testFuzzy(t *testing.T, s Storage) {
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Supermassive Black Hole"})
+	add(t, s, models.Song{BandName: "Museum", SongTitle: "Ghost"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "Far away"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Bitter End", Lyrics: "So far"})
+
+	tests := []struct {
+		filter models.SongFilter
+		want   []string
+	}{
+		{models.SongFilter{BandName: "Muce", Fuzzy: true}, []string{"Supermassive Black Hole", "Starlight", "Ghost"}},
+		{models.SongFilter{Search: "far", BandName: "Muce", Fuzzy: true}, []string{"Starlight"}},
+		{models.SongFilter{Search: "far", SongTitle: "Biter End", Fuzzy: true}, []string{"Bitter End"}},
+		{models.SongFilter{Search: "ghost", BandName: "Muce", Fuzzy: true}, nil},
+		{models.SongFilter{BandName: "Muce", Fuzzy: true, Similarity: 0.9}, nil},
+		{models.SongFilter{SongTitle: "Supermasive", Fuzzy: true}, []string{"Supermassive Black Hole"}},
+		{models.SongFilter{BandName: "muse", SongTitle: "starlite", Fuzzy: true}, []string{"Starlight"}},
+		{models.SongFilter{BandName: "Muse", Fuzzy: true}, []string{"Supermassive Black Hole", "Starlight", "Ghost"}},
+	}
+
+	for _, tt := range tests {
+		if got := titles(list(t, s, tt.filter)); !slices.Equal(got, tt.want) {
+			t.Fatalf("fuzzy %+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	var paged []string
+	filter := models.SongFilter{BandName: "Muse", Fuzzy: true, Page: 1, PageSize: 2}
+	for {
+		got := page(t, s, filter)
+		paged = append(paged, titles(got.Songs)...)
+
+		if got.NextCursor == "" {
+			break
+		}
+
+		filter.Cursor = got.NextCursor
+	}
+
+	if want := []string{"Supermassive Black Hole", "Starlight", "Ghost"}; !slices.Equal(paged, want) {
+		t.Fatalf("paging by similarity: got %v, want %v", paged, want)
+	}
+}
+
+func testClosestNames(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Supermassive Black Hole"})
+	add(t, s, models.Song{BandName: "Museum", SongTitle: "Ghost"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Bitter End"})
+
+	tests := []struct {
+		band, title string
+		threshold   float64
+		want        models.DidYouMean
+	}{
+		{"Muce", "Supermasive", 0.2, models.DidYouMean{BandName: "Muse", SongTitle: "Supermassive Black Hole"}},
+		{"Plasebo", "", 0.2, models.DidYouMean{BandName: "Placebo"}},
+		{"Muce", "", 0.9, models.DidYouMean{}},
+		{"Zzz", "Qqq", 0.2, models.DidYouMean{}},
+	}
+
+	for _, tt := range tests {
+		got, err := s.ClosestNames(ctx, tt.band, tt.title, tt.threshold)
+		if err != nil {
+			t.Fatalf("ClosestNames(%q, %q): %v", tt.band, tt.title, err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("ClosestNames(%q, %q): got %+v, want %+v", tt.band, tt.title, got, tt.want)
+		}
+	}
+}
+
+func testNames(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+	add(t, s, models.Song{BandName: "Placebo", SongTitle: "Starlight"})
+
+	tests := []struct {
+		kind models.NameKind
+		want []models.Name
+	}{
+		{models.NameBand, []models.Name{{Value: "Muse", Songs: 2}, {Value: "Placebo", Songs: 1}}},
+		{models.NameSong, []models.Name{{Value: "Hysteria", Songs: 1}, {Value: "Starlight", Songs: 2}}},
+	}
+
+	for _, tt := range tests {
+		got, err := s.Names(ctx, tt.kind)
+		if err != nil {
+			t.Fatalf("Names(%s): %v", tt.kind, err)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Fatalf("Names(%s): got %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+
+	if _, err := s.Names(ctx, "lyrics"); err == nil {
+		t.Fatalf("Names of an unknown kind: expected an error")
+	}
+}
+
+func testVerses(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "\r\nfirst\r\nline  \r\n\r\nsecond\n\n\n\nthird\n \n\n"})
+
+	for verse, want := range map[int]string{1: "first\nline", 2: "second", 3: "third"} {
+		got, err := s.GetTextSong(ctx, models.SongLyrics{BandName: "Muse", SongTitle: "Starlight", Verse: verse})
+		if err != nil {
+			t.Fatalf("GetTextSong verse %d: %v", verse, err)
+		}
+
+		if got.Text != want || got.Number != verse || got.Total != 3 || got.Kind != "verse" {
+			t.Fatalf("GetTextSong verse %d: got %+v, want %q of 3", verse, got, want)
+		}
+	}
+
+	if song, _ := s.GetSong(ctx, id); song.Lyrics != "first\nline\n\nsecond\n\nthird" {
+		t.Fatalf("stored lyrics are not normalized: %q", song.Lyrics)
+	}
+}
+
+func testVersesNotFound(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "first\n\nsecond"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Instrumental"})
+
+	requests := []models.SongLyrics{
+		{BandName: "Muse", SongTitle: "Starlight", Verse: 3},
+		{BandName: "Muse", SongTitle: "Instrumental", Verse: 1},
+		{BandName: "Muse", SongTitle: "Unknown", Verse: 1},
+	}
+
+	for _, req := range requests {
+		_, err := s.GetTextSong(ctx, req)
+		if !errors.Is(err, errs.ErrSongNotFound) {
+			t.Fatalf("GetTextSong %+v: got %v, want %v", req, err, errs.ErrSongNotFound)
+		}
+	}
+}
+
+func testVersesFollowWrites(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "first\n\nsecond"})
+
+	writes := []struct {
+		name  string
+		write func() error
+		want  []string
+	}{
+		{"patch", func() error {
+			_, err := s.PatchSong(ctx, id, models.SongPatch{Lyrics: models.Of("one\n\ntwo\n\nthree")})
+			return err
+		}, []string{"one", "two", "three"}},
+		{"legacy update", func() error {
+			_, err := s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "only"})
+			return err
+		}, []string{"only"}},
+		{"replace", func() error {
+			_, err := s.ReplaceSong(ctx, id, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "a\r\n\r\nb"})
+			return err
+		}, []string{"a", "b"}},
+		{"clear", func() error {
+			_, err := s.PatchSong(ctx, id, models.SongPatch{Lyrics: models.PatchField{Set: true, Null: true}})
+			return err
+		}, nil},
+	}
+
+	for _, w := range writes {
+		if err := w.write(); err != nil {
+			t.Fatalf("%s: %v", w.name, err)
+		}
+
+		for n, want := range w.want {
+			got, err := s.GetVerse(ctx, id, n+1)
+			if err != nil {
+				t.Fatalf("%s: GetVerse %d: %v", w.name, n+1, err)
+			}
+
+			if got.Text != want || got.Total != len(w.want) {
+				t.Fatalf("%s: GetVerse %d: got %+v, want %q of %d", w.name, n+1, got, want, len(w.want))
+			}
+		}
+
+		if _, err := s.GetVerse(ctx, id, len(w.want)+1); !errors.Is(err, errs.ErrSongNotFound) {
+			t.Fatalf("%s: verse after the last: got %v, want ErrSongNotFound", w.name, err)
+		}
+	}
+}
+
+func testUpdateEveryField(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	updates := []models.Song{
+		{BandName: "Muse", SongTitle: "Starlight", Link: "https://example.com/starlight"},
+		{BandName: "Muse", SongTitle: "Starlight", Lyrics: "far away\n\nstarlight"},
+		{BandName: "Muse", SongTitle: "Starlight", ReleaseDate: "03.09.2006"},
+	}
+
+	for _, update := range updates {
+		got, err := s.UpdateSong(ctx, update)
+		if err != nil {
+			t.Fatalf("UpdateSong %+v: %v", update, err)
+		}
+
+		if got != id {
+			t.Fatalf("UpdateSong returned id %d, want %d", got, id)
+		}
+	}
+
+	songs := list(t, s, models.SongFilter{SongTitle: "Starlight", Page: 1, PageSize: 10})
+	if len(songs) != 1 {
+		t.Fatalf("GetSongs: got %d songs, want 1", len(songs))
+	}
+
+	song := songs[0]
+	if song.Link != "https://example.com/starlight" || song.Lyrics != "far away\n\nstarlight" || song.ReleaseDate != "03.09.2006" {
+		t.Fatalf("updated song: got %+v", song)
+	}
+}
+
+func testUpdateErrors(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	_, err := s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if !errors.Is(err, errs.ErrNoChanges) {
+		t.Fatalf("UpdateSong without changes: got %v, want %v", err, errs.ErrNoChanges)
+	}
+
+	_, err = s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Unknown", Link: "https://example.com"})
+	if !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("UpdateSong unknown song: got %v, want %v", err, errs.ErrSongNotFound)
+	}
+}
+
+func testUpdateSeveralFields(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	_, err := s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight", Link: "https://example.com", Lyrics: "far away", ReleaseDate: "03.09.2006"})
+	if err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+
+	song := list(t, s, models.SongFilter{SongTitle: "Starlight"})[0]
+	if song.Link != "https://example.com" || song.Lyrics != "far away" || song.ReleaseDate != "03.09.2006" {
+		t.Fatalf("updated song: got %+v", song)
+	}
+}
+
+func testDelete(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising"})
+
+	got, err := s.DeleteSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if err != nil {
+		t.Fatalf("DeleteSong: %v", err)
+	}
+
+	if got != id {
+		t.Fatalf("DeleteSong returned id %d, want %d", got, id)
+	}
+
+	_, err = s.DeleteSong(ctx, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	if !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("DeleteSong twice: got %v, want %v", err, errs.ErrSongNotFound)
+	}
+
+	left := titles(list(t, s, models.SongFilter{Page: 1, PageSize: 10}))
+	if want := []string{"Uprising"}; !sameSet(left, want) {
+		t.Fatalf("songs after delete: got %v, want %v", left, want)
+	}
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+}
+
+func testGetSong(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", ReleaseDate: "01.12.2003", Lyrics: "It's bugging me", Link: "https://example.com"})
+
+	song, err := s.GetSong(ctx, id)
+	if err != nil {
+		t.Fatalf("GetSong: %v", err)
+	}
+
+	want := models.Song{ID: id, BandName: "Muse", SongTitle: "Hysteria", ReleaseDate: "01.12.2003", Lyrics: "It's bugging me", Link: "https://example.com", Status: models.SongStatusPending}
+	if song != want {
+		t.Fatalf("GetSong: got %+v, want %+v", song, want)
+	}
+
+	if listed := list(t, s, models.SongFilter{}); listed[0].ID != id {
+		t.Fatalf("GetSongs: got id %d, want %d", listed[0].ID, id)
+	}
+
+	_, err = s.GetSong(ctx, id+1)
+
+	var songErr *errs.SongError
+	if !errors.Is(err, errs.ErrSongNotFound) || !errors.As(err, &songErr) || songErr.ID != id+1 {
+		t.Fatalf("GetSong of a missing id: got %v, want ErrSongNotFound for id %d", err, id+1)
+	}
+}
+
+func testReplaceSong(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", ReleaseDate: "01.12.2003", Lyrics: "It's bugging me"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	replaced, err := s.ReplaceSong(ctx, id, models.Song{BandName: "Muse", SongTitle: "Hysteria (Live)", Link: "https://example.com"})
+	if err != nil {
+		t.Fatalf("ReplaceSong: %v", err)
+	}
+
+	want := models.Song{ID: id, BandName: "Muse", SongTitle: "Hysteria (Live)", Link: "https://example.com", Status: models.SongStatusPending}
+	if replaced != want {
+		t.Fatalf("ReplaceSong: got %+v, want %+v", replaced, want)
+	}
+
+	if got, _ := s.GetSong(ctx, id); got != want {
+		t.Fatalf("GetSong after replace: got %+v, want %+v", got, want)
+	}
+
+	if _, err := s.ReplaceSong(ctx, id, models.Song{BandName: "Muse", SongTitle: "Starlight"}); !errors.Is(err, errs.ErrSongExists) {
+		t.Fatalf("ReplaceSong onto another song: got %v, want ErrSongExists", err)
+	}
+
+	if _, err := s.ReplaceSong(ctx, id+100, models.Song{BandName: "Muse", SongTitle: "Uprising"}); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("ReplaceSong of a missing id: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func testPatchSong(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", ReleaseDate: "01.12.2003", Lyrics: "It's bugging me", Link: "https://example.com"})
+
+	patched, err := s.PatchSong(ctx, id, models.SongPatch{
+		BandName:    models.Of("MUSE"),
+		SongTitle:   models.Of("Hysteria (Live)"),
+		ReleaseDate: models.Of("05.06.2004"),
+		Link:        models.PatchField{Set: true, Null: true},
+	})
+	if err != nil {
+		t.Fatalf("PatchSong: %v", err)
+	}
+
+	want := models.Song{ID: id, BandName: "MUSE", SongTitle: "Hysteria (Live)", ReleaseDate: "05.06.2004", Lyrics: "It's bugging me", Status: models.SongStatusPending}
+	if patched != want {
+		t.Fatalf("PatchSong: got %+v, want %+v", patched, want)
+	}
+
+	patched, err = s.PatchSong(ctx, id, models.SongPatch{ReleaseDate: models.PatchField{Set: true, Null: true}})
+	if err != nil {
+		t.Fatalf("PatchSong clearing release date: %v", err)
+	}
+
+	if want.ReleaseDate = ""; patched != want {
+		t.Fatalf("PatchSong clearing release date: got %+v, want %+v", patched, want)
+	}
+}
+
+func testPatchErrors(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	if _, err := s.PatchSong(ctx, id, models.SongPatch{}); !errors.Is(err, errs.ErrNoChanges) {
+		t.Fatalf("empty patch: got %v, want ErrNoChanges", err)
+	}
+
+	if _, err := s.PatchSong(ctx, id, models.SongPatch{SongTitle: models.Of("Starlight"), Link: models.Of("https://example.com")}); !errors.Is(err, errs.ErrSongExists) {
+		t.Fatalf("rename onto another song: got %v, want ErrSongExists", err)
+	}
+
+	if song, _ := s.GetSong(ctx, id); song.SongTitle != "Hysteria" || song.Link != "" {
+		t.Fatalf("failed rename changed the song: %+v", song)
+	}
+
+	if _, err := s.PatchSong(ctx, id+100, models.SongPatch{Link: models.Of("https://example.com")}); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("patch of a missing id: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func testPatchLanguage(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+
+	patched, err := s.PatchSong(ctx, id, models.SongPatch{Language: models.Of("en")})
+	if err != nil || patched.Language != "en" {
+		t.Fatalf("PatchSong setting language: got %+v, %v, want language en", patched, err)
+	}
+
+	if _, err := s.UpdateSong(ctx, models.Song{BandName: "Muse", SongTitle: "Hysteria", Language: "en-GB"}); err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+
+	if songs := list(t, s, models.SongFilter{SongTitle: "Hysteria"}); len(songs) != 1 || songs[0].Language != "en-GB" {
+		t.Fatalf("GetSongs after UpdateSong: got %+v, want language en-GB", songs)
+	}
+
+	patched, err = s.PatchSong(ctx, id, models.SongPatch{Language: models.PatchField{Set: true, Null: true}})
+	if err != nil || patched.Language != "" {
+		t.Fatalf("PatchSong clearing language: got %+v, %v, want no language", patched, err)
+	}
+
+	if song, err := s.GetSong(ctx, id); err != nil || song.Language != "" {
+		t.Fatalf("GetSong after clearing language: got %+v, %v, want no language", song, err)
+	}
+}
+
+func testDeleteByID(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria"})
+
+	if err := s.DeleteSongByID(ctx, id); err != nil {
+		t.Fatalf("DeleteSongByID: %v", err)
+	}
+
+	if _, err := s.GetSong(ctx, id); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetSong after delete: got %v, want ErrSongNotFound", err)
+	}
+
+	if err := s.DeleteSongByID(ctx, id); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("second DeleteSongByID: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func add(t *testing.T, s Storage, song models.Song) int64 {
+	t.Helper()
+
+	id, err := s.AddNewSong(context.Background(), song)
+	if err != nil {
+		t.Fatalf("AddNewSong %s - %s: %v", song.BandName, song.SongTitle, err)
+	}
+
+	return id
+}
+
+func list(t *testing.T, s Storage, filter models.SongFilter) []models.Song {
+	t.Helper()
+
+	if filter.Page == 0 && filter.PageSize == 0 {
+		filter.Page, filter.PageSize = 1, 100
+	}
+
+	return page(t, s, filter).Songs
+}
+
+func page(t *testing.T, s Storage, filter models.SongFilter) models.SongList {
+	t.Helper()
+
+	songs, err := s.GetSongs(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetSongs %+v: %v", filter, err)
+	}
+
+	return songs
+}
+
+func titles(songs []models.Song) []string {
+	result := make([]string, 0, len(songs))
+	for _, song := range songs {
+		result = append(result, song.SongTitle)
+	}
+
+	return result
+}
+
+func sameSet(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	set := make(map[string]bool, len(got))
+	for _, item := range got {
+		set[item] = true
+	}
+
+	for _, item := range want {
+		if !set[item] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testVerseKinds(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "" +
+		"[Intro]\nhum\n\n" +
+		"far away\n\n" +
+		"Ooh\nYou set my soul alight\n\n" +
+		"[Verse 2: Matt]\nnear\n[Pre-Chorus]\nhold you\n\n" +
+		"ooh\nyou set  my soul alight\n\n" +
+		"[Bridge]\n\n" +
+		"[Chorus]"})
+
+	want := []models.Verse{
+		{Number: 1, Kind: "intro", Text: "hum"},
+		{Number: 2, Kind: "verse", Text: "far away"},
+		{Number: 3, Kind: "chorus", Text: "Ooh\nYou set my soul alight"},
+		{Number: 4, Kind: "verse", Text: "near"},
+		{Number: 5, Kind: "pre-chorus", Text: "hold you"},
+		{Number: 6, Kind: "chorus", Text: "ooh\nyou set  my soul alight"},
+		{Number: 7, Kind: "chorus", Text: "ooh\nyou set  my soul alight"},
+	}
+
+	got, err := s.GetVerses(ctx, id)
+	if err != nil {
+		t.Fatalf("GetVerses: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("GetVerses: got %+v, want %d verses", got, len(want))
+	}
+
+	for i := range want {
+		want[i].Total = len(want)
+		if got[i] != want[i] {
+			t.Fatalf("GetVerses: verse %d: got %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+
+	byName, err := s.GetTextVerses(ctx, models.SongLyrics{BandName: "Muse", SongTitle: "Starlight"})
+	if err != nil || !slices.Equal(byName, got) {
+		t.Fatalf("GetTextVerses: got %+v, %v, want the verses GetVerses returned", byName, err)
+	}
+
+	if _, err := s.GetTextVerses(ctx, models.SongLyrics{BandName: "Muse", SongTitle: "Hysteria"}); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetTextVerses of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	empty := add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising"})
+	if got, err := s.GetVerses(ctx, empty); err != nil || len(got) != 0 {
+		t.Fatalf("GetVerses without lyrics: got %+v, %v, want no verses", got, err)
+	}
+
+	if _, err := s.GetVerses(ctx, id+empty+1); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetVerses of a missing song: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func testSyncedLyrics(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Lyrics: "far away"})
+
+	if _, err := s.GetSyncedLyrics(ctx, id); !errors.Is(err, errs.ErrNoSyncedLyrics) {
+		t.Fatalf("GetSyncedLyrics before an upload: got %v, want ErrNoSyncedLyrics", err)
+	}
+
+	for _, upload := range [][]models.SyncedLine{
+		{{TimeMs: 1000, Text: "far away"}, {TimeMs: 2500, Text: ""}, {TimeMs: 4000, Text: "this ship"}},
+		{{TimeMs: 500, Text: "my life"}, {TimeMs: 750, Text: "you electrify"}},
+	} {
+		if err := s.ReplaceSyncedLyrics(ctx, id, upload); err != nil {
+			t.Fatalf("ReplaceSyncedLyrics: %v", err)
+		}
+
+		got, err := s.GetSyncedLyrics(ctx, id)
+		if err != nil {
+			t.Fatalf("GetSyncedLyrics: %v", err)
+		}
+
+		for i := range upload {
+			upload[i].Number = i + 1
+		}
+
+		if !slices.Equal(got, upload) {
+			t.Fatalf("GetSyncedLyrics: got %+v, want %+v", got, upload)
+		}
+	}
+
+	if song, err := s.GetSong(ctx, id); err != nil || song.Lyrics != "far away" {
+		t.Fatalf("GetSong after an upload: got %+v, %v, want the plain lyrics kept", song, err)
+	}
+
+	if err := s.DeleteSyncedLyrics(ctx, id); err != nil {
+		t.Fatalf("DeleteSyncedLyrics: %v", err)
+	}
+
+	if err := s.DeleteSyncedLyrics(ctx, id); !errors.Is(err, errs.ErrNoSyncedLyrics) {
+		t.Fatalf("DeleteSyncedLyrics twice: got %v, want ErrNoSyncedLyrics", err)
+	}
+
+	missing := id + 1
+	if err := s.ReplaceSyncedLyrics(ctx, missing, []models.SyncedLine{{Text: "x"}}); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("ReplaceSyncedLyrics of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	if _, err := s.GetSyncedLyrics(ctx, missing); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetSyncedLyrics of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	if err := s.DeleteSyncedLyrics(ctx, missing); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("DeleteSyncedLyrics of a missing song: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func testTranslations(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight", Language: "en", Lyrics: "far away"})
+
+	if song, err := s.GetSong(ctx, id); err != nil || song.Language != "en" {
+		t.Fatalf("GetSong: got %+v, %v, want language en", song, err)
+	}
+
+	if _, err := s.GetTranslation(ctx, id, "ru"); !errors.Is(err, errs.ErrNoTranslation) {
+		t.Fatalf("GetTranslation before a save: got %v, want ErrNoTranslation", err)
+	}
+
+	for _, tt := range []struct {
+		language, text string
+		created        bool
+	}{
+		{"ru", "далеко", true},
+		{"de", "weit weg", true},
+		{"ru", "так далеко", false},
+	} {
+		created, err := s.SaveTranslation(ctx, id, tt.language, tt.text)
+		if err != nil || created != tt.created {
+			t.Fatalf("SaveTranslation %s: got %v, %v, want created %v", tt.language, created, err, tt.created)
+		}
+	}
+
+	want := models.Translation{SongID: id, Language: "ru", Lyrics: "так далеко"}
+	if got, err := s.GetTranslation(ctx, id, "ru"); err != nil || got != want {
+		t.Fatalf("GetTranslation: got %+v, %v, want %+v", got, err, want)
+	}
+
+	got, err := s.GetTextTranslation(ctx, models.SongLyrics{BandName: "Muse", SongTitle: "Starlight", Lang: "ru"})
+	if err != nil || got != want {
+		t.Fatalf("GetTextTranslation: got %+v, %v, want %+v", got, err, want)
+	}
+
+	translations, err := s.GetTranslations(ctx, id)
+	if err != nil {
+		t.Fatalf("GetTranslations: %v", err)
+	}
+
+	if languages := []string{"de", "ru"}; len(translations) != len(languages) ||
+		translations[0].Language != languages[0] || translations[1].Language != languages[1] {
+		t.Fatalf("GetTranslations: got %+v, want languages %v", translations, languages)
+	}
+
+	if err := s.DeleteTranslation(ctx, id, "ru"); err != nil {
+		t.Fatalf("DeleteTranslation: %v", err)
+	}
+
+	if err := s.DeleteTranslation(ctx, id, "ru"); !errors.Is(err, errs.ErrNoTranslation) {
+		t.Fatalf("DeleteTranslation twice: got %v, want ErrNoTranslation", err)
+	}
+
+	other := add(t, s, models.Song{BandName: "Muse", SongTitle: "Uprising"})
+	if translations, err := s.GetTranslations(ctx, other); err != nil || len(translations) != 0 {
+		t.Fatalf("GetTranslations of an untranslated song: got %+v, %v, want none", translations, err)
+	}
+
+	missing := other + 1
+	if _, err := s.SaveTranslation(ctx, missing, "ru", "x"); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("SaveTranslation of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	if _, err := s.GetTranslation(ctx, missing, "ru"); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetTranslation of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	if _, err := s.GetTranslations(ctx, missing); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("GetTranslations of a missing song: got %v, want ErrSongNotFound", err)
+	}
+
+	if err := s.DeleteTranslation(ctx, missing, "ru"); !errors.Is(err, errs.ErrSongNotFound) {
+		t.Fatalf("DeleteTranslation of a missing song: got %v, want ErrSongNotFound", err)
+	}
+}
+
+func testJobsComplete(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	bare := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	given := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", Lyrics: "It's bugging me"})
+
+	details := models.Song{ReleaseDate: "03.09.2006", Lyrics: "far away\n\nstarlight", Link: "https://example.com"}
+
+	for range 2 {
+		job := claim(t, s, time.Minute)
+		if job.Attempts != 1 {
+			t.Fatalf("ClaimEnrichmentJob: got attempt %d, want 1", job.Attempts)
+		}
+
+		if want := map[int64]string{bare: "Starlight", given: "Hysteria"}[job.SongID]; job.BandName != "Muse" || job.SongTitle != want {
+			t.Fatalf("ClaimEnrichmentJob: got %+v, want a job of one of the added songs", job)
+		}
+
+		if err := s.CompleteEnrichmentJob(ctx, job.ID, details); err != nil {
+			t.Fatalf("CompleteEnrichmentJob: %v", err)
+		}
+	}
+
+	if _, err := s.ClaimEnrichmentJob(ctx, time.Minute); !errors.Is(err, errs.ErrNoJobs) {
+		t.Fatalf("ClaimEnrichmentJob after completion: got %v, want ErrNoJobs", err)
+	}
+
+	want := models.Song{ID: bare, BandName: "Muse", SongTitle: "Starlight", Status: models.SongStatusEnriched,
+		ReleaseDate: details.ReleaseDate, Lyrics: details.Lyrics, Link: details.Link}
+	if song, err := s.GetSong(ctx, bare); err != nil || song != want {
+		t.Fatalf("GetSong of an enriched song: got %+v, %v, want %+v", song, err, want)
+	}
+
+	if verses, err := s.GetVerses(ctx, bare); err != nil || len(verses) != 2 {
+		t.Fatalf("GetVerses of an enriched song: got %+v, %v, want the 2 upstream verses", verses, err)
+	}
+
+	want = models.Song{ID: given, BandName: "Muse", SongTitle: "Hysteria", Status: models.SongStatusEnriched,
+		ReleaseDate: details.ReleaseDate, Lyrics: "It's bugging me", Link: details.Link}
+	if song, err := s.GetSong(ctx, given); err != nil || song != want {
+		t.Fatalf("GetSong of a song with lyrics: got %+v, %v, want the client lyrics kept in %+v", song, err, want)
+	}
+
+	if verses, err := s.GetVerses(ctx, given); err != nil || len(verses) != 1 || verses[0].Text != "It's bugging me" {
+		t.Fatalf("GetVerses of a song with lyrics: got %+v, %v, want the client verse", verses, err)
+	}
+}
+
+func testJobsLease(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	// A worker that stops before its lease runs out leaves the job to be claimed again.
+	first := claim(t, s, -time.Second)
+
+	second := claim(t, s, time.Minute)
+	if second.ID != first.ID || second.SongID != id || second.Attempts != 2 {
+		t.Fatalf("ClaimEnrichmentJob after the lease expired: got %+v, want job %d at attempt 2", second, first.ID)
+	}
+
+	if _, err := s.ClaimEnrichmentJob(ctx, time.Minute); !errors.Is(err, errs.ErrNoJobs) {
+		t.Fatalf("ClaimEnrichmentJob of a leased job: got %v, want ErrNoJobs", err)
+	}
+}
+
+func testJobsRetry(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+
+	job := claim(t, s, time.Minute)
+
+	if err := s.RetryEnrichmentJob(ctx, job.ID, time.Hour, "timeout"); err != nil {
+		t.Fatalf("RetryEnrichmentJob: %v", err)
+	}
+
+	if _, err := s.ClaimEnrichmentJob(ctx, time.Minute); !errors.Is(err, errs.ErrNoJobs) {
+		t.Fatalf("ClaimEnrichmentJob before the retry is due: got %v, want ErrNoJobs", err)
+	}
+
+	if err := s.RetryEnrichmentJob(ctx, job.ID, -time.Second, "timeout"); err != nil {
+		t.Fatalf("RetryEnrichmentJob: %v", err)
+	}
+
+	if again := claim(t, s, time.Minute); again.ID != job.ID || again.Attempts != 2 {
+		t.Fatalf("ClaimEnrichmentJob of a due retry: got %+v, want job %d at attempt 2", again, job.ID)
+	}
+}
+
+func testJobsDeadLetter(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	bare := add(t, s, models.Song{BandName: "Muse", SongTitle: "Starlight"})
+	given := add(t, s, models.Song{BandName: "Muse", SongTitle: "Hysteria", ReleaseDate: "01.12.2003", Lyrics: "It's bugging me", Link: "https://example.com"})
+
+	for range 2 {
+		job := claim(t, s, time.Minute)
+
+		if err := s.FailEnrichmentJob(ctx, job.ID, "song not found"); err != nil {
+			t.Fatalf("FailEnrichmentJob: %v", err)
+		}
+	}
+
+	if _, err := s.ClaimEnrichmentJob(ctx, time.Minute); !errors.Is(err, errs.ErrNoJobs) {
+		t.Fatalf("ClaimEnrichmentJob of dead jobs: got %v, want ErrNoJobs", err)
+	}
+
+	for id, want := range map[int64]string{bare: models.SongStatusFailed, given: models.SongStatusEnriched} {
+		if song, err := s.GetSong(ctx, id); err != nil || song.Status != want {
+			t.Fatalf("GetSong after a dead job: got %+v, %v, want status %q", song, err, want)
+		}
+	}
+
+	count, err := s.RequeueFailedJobs(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("RequeueFailedJobs: got %d, %v, want 2", count, err)
+	}
+
+	if song, err := s.GetSong(ctx, bare); err != nil || song.Status != models.SongStatusPending {
+		t.Fatalf("GetSong after a requeue: got %+v, %v, want status pending", song, err)
+	}
+
+	for range 2 {
+		if job := claim(t, s, time.Minute); job.Attempts != 1 {
+			t.Fatalf("ClaimEnrichmentJob after a requeue: got attempt %d, want 1", job.Attempts)
+		}
+	}
+
+	if count, err := s.RequeueFailedJobs(ctx); err != nil || count != 0 {
+		t.Fatalf("RequeueFailedJobs without dead jobs: got %d, %v, want 0", count, err)
+	}
+}
+
+func claim(t *testing.T, s Storage, lease time.Duration) models.EnrichmentJob {
+	t.Helper()
+
+	job, err := s.ClaimEnrichmentJob(context.Background(), lease)
+	if err != nil {
+		t.Fatalf("ClaimEnrichmentJob: %v", err)
+	}
+
+	return job
+}
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS songs_lyrics_tsv_russian;
+
+DROP INDEX IF EXISTS songs_lyrics_tsv_english;
+
+DROP INDEX IF EXISTS songs_lyrics_tsv_simple;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS lyrics_tsv_russian,
+    DROP COLUMN IF EXISTS lyrics_tsv_english,
+    DROP COLUMN IF EXISTS lyrics_tsv_simple;
@@ -0,0 +1,13 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS lyrics_tsv_simple tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(lyrics, ''))) STORED,
+    ADD COLUMN IF NOT EXISTS lyrics_tsv_english tsvector
+        GENERATED ALWAYS AS (to_tsvector('english', COALESCE(lyrics, ''))) STORED,
+    ADD COLUMN IF NOT EXISTS lyrics_tsv_russian tsvector
+        GENERATED ALWAYS AS (to_tsvector('russian', COALESCE(lyrics, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS songs_lyrics_tsv_simple ON songs USING GIN (lyrics_tsv_simple);
+
+CREATE INDEX IF NOT EXISTS songs_lyrics_tsv_english ON songs USING GIN (lyrics_tsv_english);
+
+CREATE INDEX IF NOT EXISTS songs_lyrics_tsv_russian ON songs USING GIN (lyrics_tsv_russian);
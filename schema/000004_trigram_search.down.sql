@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS songs_song_trgm;
+
+DROP INDEX IF EXISTS songs_band_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
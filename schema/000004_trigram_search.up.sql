@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS songs_band_trgm ON songs USING GIN (band gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS songs_song_trgm ON songs USING GIN (song gin_trgm_ops);
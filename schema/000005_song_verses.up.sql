@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS
+    song_verses (
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        position INTEGER NOT NULL CHECK (position > 0),
+        kind TEXT NOT NULL DEFAULT 'verse',
+        text TEXT NOT NULL,
+        PRIMARY KEY (song_id, position)
+    );
+
+-- The verses of songs stored before are parsed from their lyrics at startup. The lyrics are kept
+-- as they are, so dropping the table loses nothing.
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS song_lyrics_translations;
+
+ALTER TABLE songs DROP COLUMN IF EXISTS language;
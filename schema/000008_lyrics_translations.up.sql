@@ -0,0 +1,10 @@
+ALTER TABLE songs ADD COLUMN IF NOT EXISTS language TEXT;
+
+CREATE TABLE IF NOT EXISTS
+    song_lyrics_translations (
+        song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+        language TEXT NOT NULL CHECK (language <> ''),
+        lyrics TEXT NOT NULL,
+        updated TIMESTAMP NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (song_id, language)
+    );
@@ -0,0 +1,3 @@
+-- A lone marker no longer makes an empty verse. Songs that have one are parsed again at startup.
+DELETE FROM song_verses
+WHERE song_id IN (SELECT song_id FROM song_verses WHERE text = '');